@@ -0,0 +1,733 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gigawattio/metaflector"
+)
+
+// textMarshaler mirrors encoding.TextMarshaler, whose implementations are
+// terminal when found in slices.
+var textMarshaler = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(0, nil, "MarshalText", types.NewSignature(nil, nil, types.NewTuple(
+		types.NewVar(0, nil, "", types.NewSlice(types.Typ[types.Byte])),
+		types.NewVar(0, nil, "", types.Universe.Lookup("error").Type()),
+	), false)),
+}, nil).Complete()
+
+// fieldLister and fieldGetter mirror metaflector.FieldLister and
+// metaflector.FieldGetter, whose implementations supply their own fields at
+// run time and so are left to reflection.
+var (
+	fieldLister = types.NewInterfaceType([]*types.Func{
+		types.NewFunc(0, nil, "MetaflectorFields", types.NewSignature(nil, nil, types.NewTuple(
+			types.NewVar(0, nil, "", types.NewSlice(types.Typ[types.String])),
+		), false)),
+	}, nil).Complete()
+
+	fieldGetter = types.NewInterfaceType([]*types.Func{
+		types.NewFunc(0, nil, "MetaflectorField", types.NewSignature(nil,
+			types.NewTuple(types.NewVar(0, nil, "", types.Typ[types.String])),
+			types.NewTuple(
+				types.NewVar(0, nil, "", types.NewInterfaceType(nil, nil).Complete()),
+				types.NewVar(0, nil, "", types.Typ[types.Bool]),
+			), false)),
+	}, nil).Complete()
+)
+
+type generator struct {
+	pkg     *types.Package
+	sep     string
+	tagName string
+	r       *metaflector.Reflector
+	root    string // The first type generated for, naming the appenders.
+
+	imports   map[string]string // Import paths by package name.
+	methods   bytes.Buffer
+	funcs     bytes.Buffer
+	appenders map[string]string // Appender function names by type.
+	names     map[string]struct{}
+	pending   []types.Type
+	vars      int
+}
+
+// field is a struct field which participates in traversal, named as it is
+// in paths.
+type field struct {
+	goName string
+	name   string
+	typ    types.Type
+}
+
+// accessor is a terminal field path along with the fields leading to it.
+type accessor struct {
+	path   string
+	fields []field
+	fanOut bool // True if the path passes through a slice or array.
+}
+
+func newGenerator(pkg *types.Package, sep string, tagName string) *generator {
+	return &generator{
+		pkg:       pkg,
+		sep:       sep,
+		tagName:   tagName,
+		r:         metaflector.New(metaflector.WithSeparator(sep)),
+		imports:   map[string]string{},
+		appenders: map[string]string{},
+		names:     map[string]struct{}{},
+	}
+}
+
+// generate writes the accessor methods for the named struct type.
+func (g *generator) generate(t *types.Named) {
+	var (
+		name      = t.Obj().Name()
+		accessors = []accessor{}
+	)
+	if g.root == "" {
+		g.root = name
+	}
+	g.accessors(t, nil, nil, false, map[string]struct{}{}, &accessors)
+
+	if !g.dynamic(t, map[string]struct{}{}) {
+		fmt.Fprintf(&g.methods, "// MetaflectorTerminalFields implements metaflector.StaticFields.\n")
+		fmt.Fprintf(&g.methods, "func (x %s) MetaflectorTerminalFields(sep string, tagName string) ([]string, bool) {\n", name)
+		g.writeCheck(&g.methods, "nil, false")
+		fmt.Fprintf(&g.methods, "return %s(&x, \"\", []string{}), true\n}\n\n", g.appender(t))
+	}
+
+	fmt.Fprintf(&g.methods, "// MetaflectorGet implements metaflector.StaticGetter.\n")
+	fmt.Fprintf(&g.methods, "func (x %s) MetaflectorGet(path string, sep string, tagName string) (interface{}, bool) {\n", name)
+	g.writeCheck(&g.methods, "nil, false")
+	fmt.Fprintf(&g.methods, "switch path {\n")
+	for _, a := range accessors {
+		fmt.Fprintf(&g.methods, "case %s:\n", strconv.Quote(a.path))
+		g.writeGet(&g.methods, "x", t, a.fields, func(value string) string {
+			return "return " + value + ", true"
+		})
+	}
+	fmt.Fprintf(&g.methods, "}\nreturn nil, false\n}\n\n")
+
+	fmt.Fprintf(&g.methods, "// MetaflectorSet implements metaflector.StaticSetter.\n")
+	fmt.Fprintf(&g.methods, "func (x *%s) MetaflectorSet(path string, value interface{}, sep string, tagName string) bool {\n", name)
+	g.writeCheck(&g.methods, "false")
+	fmt.Fprintf(&g.methods, "switch path {\n")
+	for _, a := range accessors {
+		if !a.fanOut {
+			g.writeSet(&g.methods, a)
+		}
+	}
+	fmt.Fprintf(&g.methods, "}\nreturn false\n}\n\n")
+}
+
+// writeCheck writes a guard returning result unless called with the
+// separator and tag name the code is being generated for.
+func (g *generator) writeCheck(buf *bytes.Buffer, result string) {
+	fmt.Fprintf(buf, "if sep != %s || tagName != %s {\nreturn %s\n}\n", strconv.Quote(g.sep), strconv.Quote(g.tagName), result)
+}
+
+// source assembles and formats the generated file.
+func (g *generator) source(args string) ([]byte, error) {
+	// Appenders may refer to further appenders.
+	for len(g.pending) > 0 {
+		t := g.pending[0]
+		g.pending = g.pending[1:]
+		g.writeAppender(t)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by \"metaflector-gen %s\"; DO NOT EDIT.\n\n", args)
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg.Name())
+	if len(g.imports) > 0 {
+		names := make([]string, 0, len(g.imports))
+		for name := range g.imports {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return g.imports[names[i]] < g.imports[names[j]] })
+		fmt.Fprintf(&buf, "import (\n")
+		for _, name := range names {
+			path := g.imports[name]
+			if name == path[strings.LastIndex(path, "/")+1:] {
+				fmt.Fprintf(&buf, "%s\n", strconv.Quote(path))
+			} else {
+				fmt.Fprintf(&buf, "%s %s\n", name, strconv.Quote(path))
+			}
+		}
+		fmt.Fprintf(&buf, ")\n\n")
+	}
+	buf.Write(g.methods.Bytes())
+	buf.Write(g.funcs.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s", err)
+	}
+	return src, nil
+}
+
+// fields returns the fields of struct type t which participate in traversal,
+// as metaflector.Reflector does.
+func (g *generator) fields(t types.Type) []field {
+	s := t.Underlying().(*types.Struct)
+	fields := []field{}
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		if !f.Exported() || f.Anonymous() {
+			continue
+		}
+		name := f.Name()
+		if g.tagName != "" {
+			if tag := strings.Split(reflect.StructTag(s.Tag(i)).Get(g.tagName), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, field{goName: f.Name(), name: name, typ: f.Type()})
+	}
+	return fields
+}
+
+// accessors collects the terminal field paths beneath struct type t, which is
+// reached via the given fields.  Recursive types are expanded only once per
+// branch.
+func (g *generator) accessors(t types.Type, components []string, fields []field, fanOut bool, seen map[string]struct{}, out *[]accessor) {
+	if isCustom(t) {
+		return
+	}
+	key := types.TypeString(t, nil)
+	if _, ok := seen[key]; ok {
+		return
+	}
+	seen[key] = struct{}{}
+	defer delete(seen, key)
+
+	for _, f := range g.fields(t) {
+		var (
+			childComponents = append(components[:len(components):len(components)], f.name)
+			childFields     = append(fields[:len(fields):len(fields)], f)
+			terminal        = func(fanOut bool) {
+				*out = append(*out, accessor{path: g.r.JoinPath(childComponents...), fields: childFields, fanOut: fanOut})
+			}
+		)
+		// Components following a slice would be mistaken for indexes or
+		// wildcards.
+		if fanOut && !plainName(f.name) {
+			continue
+		}
+
+		base, ok := deref(f.typ)
+		if !ok || isCustom(f.typ) {
+			continue
+		}
+		switch u := base.Underlying().(type) {
+		case *types.Struct:
+			g.accessors(base, childComponents, childFields, fanOut, seen, out)
+
+		case *types.Slice, *types.Array:
+			elem := u.(interface{ Elem() types.Type }).Elem()
+			if isInterface(elem) {
+				continue
+			}
+			if !hasFields(elem) {
+				terminal(fanOut)
+				continue
+			}
+			if s := derefAll(elem); isStruct(s) {
+				g.accessors(s, childComponents, childFields, true, seen, out)
+			}
+
+		case *types.Map:
+			if !isObject(u) {
+				terminal(fanOut)
+			}
+
+		case *types.Basic:
+			if isTerminalBasic(u) {
+				terminal(fanOut)
+			}
+		}
+	}
+}
+
+// dynamic returns true if the terminal fields of struct type t can't be
+// listed statically, because they depend on the contents of generic objects
+// or lists, or on FieldLister and FieldGetter implementations, or involve
+// types which can't be named by the generated code.
+func (g *generator) dynamic(t types.Type, seen map[string]struct{}) bool {
+	if !g.nameable(t) || isCustom(t) {
+		return true
+	}
+	key := types.TypeString(t, nil)
+	if _, ok := seen[key]; ok {
+		return false
+	}
+	seen[key] = struct{}{}
+
+	for _, f := range g.fields(t) {
+		base, ok := deref(f.typ)
+		if !ok || isCustom(f.typ) {
+			return true
+		}
+		switch u := base.Underlying().(type) {
+		case *types.Struct:
+			if g.dynamic(base, seen) {
+				return true
+			}
+		case *types.Slice, *types.Array:
+			elem := u.(interface{ Elem() types.Type }).Elem()
+			if isInterface(elem) {
+				return true
+			}
+			if s := derefAll(elem); hasFields(elem) && isStruct(s) && g.dynamic(s, seen) {
+				return true
+			}
+		case *types.Map:
+			if isObject(u) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appender returns the name of the function appending the terminal fields of
+// struct type t, arranging for it to be written.
+func (g *generator) appender(t types.Type) string {
+	key := types.TypeString(t, nil)
+	if name, ok := g.appenders[key]; ok {
+		return name
+	}
+
+	// Appenders are named after the file's first type, so that files
+	// generated for other separators or tag names don't collide.
+	base := "metaflectorFields" + g.root
+	if named, ok := t.(*types.Named); ok {
+		var name string
+		if pkg := named.Obj().Pkg(); pkg != nil && pkg != g.pkg {
+			name += exportedName(pkg.Name())
+		}
+		if name += exportedName(named.Obj().Name()); name != g.root {
+			base += "_" + name
+		}
+	} else {
+		base += "_"
+	}
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := g.names[name]; !taken {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+
+	g.names[name] = struct{}{}
+	g.appenders[key] = name
+	g.pending = append(g.pending, t)
+	return name
+}
+
+// writeAppender writes the function appending the terminal fields of struct
+// type t, mirroring the traversal of metaflector.EachField.
+func (g *generator) writeAppender(t types.Type) {
+	var (
+		buf  = &g.funcs
+		name = g.appenders[types.TypeString(t, nil)]
+		body bytes.Buffer
+		pre  bool // Whether the separated prefix is used.
+	)
+
+	for _, f := range g.fields(t) {
+		var (
+			access    = "x." + f.goName
+			component = g.r.JoinPath(f.name)
+			path      string
+		)
+		if strings.HasPrefix(component, "[") {
+			path = "prefix + " + strconv.Quote(component)
+		} else {
+			path = "pre + " + strconv.Quote(component)
+			pre = true
+		}
+
+		base, _ := deref(f.typ)
+		isPtr := base != f.typ
+
+		switch u := base.Underlying().(type) {
+		case *types.Struct:
+			if isPtr {
+				fmt.Fprintf(&body, "if %s != nil {\nout = %s(%s, %s, out)\n}\n", access, g.appender(base), access, path)
+			} else {
+				fmt.Fprintf(&body, "out = %s(&%s, %s, out)\n", g.appender(base), access, path)
+			}
+
+		case *types.Slice, *types.Array:
+			elem := u.(interface{ Elem() types.Type }).Elem()
+			if !hasFields(elem) {
+				fmt.Fprintf(&body, "out = append(out, %s)\n", path)
+				continue
+			}
+			s := derefAll(elem)
+			if !isStruct(s) {
+				// Lists of lists have no resolvable structure.
+				continue
+			}
+			if a, ok := u.(*types.Array); ok && a.Len() == 0 {
+				continue
+			}
+
+			list := access
+			if isPtr {
+				fmt.Fprintf(&body, "if %s != nil {\n", access)
+				list = "(*" + access + ")"
+			}
+
+			// The structure is that of the first non-nil element, provided
+			// whatever it points to isn't nil either.
+			if depth := pointerDepth(elem); depth == 0 {
+				fmt.Fprintf(&body, "if len(%s) > 0 {\nout = %s(&%s[0], %s, out)\n}\n", list, g.appender(s), list, path)
+			} else {
+				conds := []string{}
+				for i := 1; i < depth; i++ {
+					conds = append(conds, strings.Repeat("*", i)+"e != nil")
+				}
+				fmt.Fprintf(&body, "for _, e := range %s {\nif e != nil {\n", list)
+				call := fmt.Sprintf("out = %s(%se, %s, out)\n", g.appender(s), strings.Repeat("*", depth-1), path)
+				if len(conds) > 0 {
+					fmt.Fprintf(&body, "if %s {\n%s}\n", strings.Join(conds, " && "), call)
+				} else {
+					body.WriteString(call)
+				}
+				fmt.Fprintf(&body, "break\n}\n}\n")
+			}
+
+			if isPtr {
+				fmt.Fprintf(&body, "}\n")
+			}
+
+		case *types.Map:
+			fmt.Fprintf(&body, "out = append(out, %s)\n", path)
+
+		case *types.Basic:
+			if isTerminalBasic(u) {
+				fmt.Fprintf(&body, "out = append(out, %s)\n", path)
+			}
+		}
+	}
+
+	fmt.Fprintf(buf, "func %s(x *%s, prefix string, out []string) []string {\n", name, g.typeName(t))
+	if pre {
+		fmt.Fprintf(buf, "pre := prefix\nif prefix != \"\" {\npre += %s\n}\n", strconv.Quote(g.sep))
+	}
+	buf.Write(body.Bytes())
+	fmt.Fprintf(buf, "return out\n}\n\n")
+}
+
+// writeGet writes the statements resolving the remaining fields against the
+// value of expr, of type t, in the same way as metaflector.Get: nil pointers
+// yield nil, slices and arrays are fanned out over (yielding nil for their nil
+// elements), and terminal values are converted as they are by Get.  sink
+// returns the statement consuming a resolved value.
+func (g *generator) writeGet(buf *bytes.Buffer, expr string, t types.Type, fields []field, sink func(value string) string) {
+	if len(fields) == 0 {
+		switch t.Underlying().(type) {
+		case *types.Slice, *types.Array:
+			g.vars++
+			var (
+				out = "out" + strconv.Itoa(g.vars)
+				e   = "e" + strconv.Itoa(g.vars)
+			)
+			fmt.Fprintf(buf, "{\n%s := make([]interface{}, 0, len(%s))\nfor _, %s := range %s {\n%s = append(%s, %s)\n}\n%s\n}\n", out, expr, e, expr, out, out, e, sink(out))
+		default:
+			fmt.Fprintf(buf, "%s\n", sink(convert(expr, t)))
+		}
+		return
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		fmt.Fprintf(buf, "if %s == nil {\n%s\n} else {\n", expr, sink("nil"))
+		g.writeGet(buf, "(*"+expr+")", u.Elem(), fields, sink)
+		fmt.Fprintf(buf, "}\n")
+
+	case *types.Struct:
+		g.writeGet(buf, expr+"."+fields[0].goName, fields[0].typ, fields[1:], sink)
+
+	case *types.Slice, *types.Array:
+		g.vars++
+		var (
+			out   = "out" + strconv.Itoa(g.vars)
+			e     = "e" + strconv.Itoa(g.vars)
+			elem  = u.(interface{ Elem() types.Type }).Elem()
+			depth = pointerDepth(elem)
+			conds = []string{}
+		)
+		for i := 0; i < depth; i++ {
+			conds = append(conds, strings.Repeat("*", i)+e+" != nil")
+		}
+		fmt.Fprintf(buf, "{\n%s := []interface{}{}\nfor _, %s := range %s {\n", out, e, expr)
+		if len(conds) > 0 {
+			fmt.Fprintf(buf, "if %s {\n", strings.Join(conds, " && "))
+		}
+		deref := e
+		if depth > 0 {
+			deref = "(" + strings.Repeat("*", depth) + e + ")"
+		}
+		g.writeGet(buf, deref, derefAll(elem), fields, func(value string) string {
+			return out + " = append(" + out + ", " + value + ")"
+		})
+		if len(conds) > 0 {
+			fmt.Fprintf(buf, "} else {\n%s = append(%s, nil)\n}\n", out, out)
+		}
+		fmt.Fprintf(buf, "}\n%s\n}\n", sink(out))
+	}
+}
+
+// writeSet writes the case assigning to the terminal field at a, allocating
+// nil pointers along the way.  Nothing is written if the types involved can't
+// be named.
+func (g *generator) writeSet(buf *bytes.Buffer, a accessor) {
+	last := a.fields[len(a.fields)-1]
+	if !g.nameable(last.typ) {
+		return
+	}
+
+	var (
+		body bytes.Buffer
+		expr = "x"
+	)
+	for i, f := range a.fields {
+		expr += "." + f.goName
+		if i == len(a.fields)-1 {
+			break
+		}
+		for t := f.typ; ; {
+			p, ok := t.Underlying().(*types.Pointer)
+			if !ok {
+				break
+			}
+			if !g.nameable(p.Elem()) {
+				return
+			}
+			fmt.Fprintf(&body, "if %s == nil {\n%s = new(%s)\n}\n", expr, expr, g.typeName(p.Elem()))
+			expr = "(*" + expr + ")"
+			t = p.Elem()
+		}
+	}
+
+	fmt.Fprintf(buf, "case %s:\n", strconv.Quote(a.path))
+	fmt.Fprintf(buf, "v, ok := value.(%s)\nif !ok {\nreturn false\n}\n", g.typeName(last.typ))
+	buf.Write(body.Bytes())
+	fmt.Fprintf(buf, "%s = v\nreturn true\n", expr)
+}
+
+// typeName returns the name of t in the generated code, importing its
+// package as necessary.
+func (g *generator) typeName(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == g.pkg {
+			return ""
+		}
+		name := pkg.Name()
+		for i := 2; ; i++ {
+			if path, ok := g.imports[name]; !ok || path == pkg.Path() {
+				break
+			}
+			name = pkg.Name() + strconv.Itoa(i)
+		}
+		g.imports[name] = pkg.Path()
+		return name
+	})
+}
+
+// nameable returns true if t can be named by code in the generated package,
+// i.e. it doesn't refer to other packages' unexported types.
+func (g *generator) nameable(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Named:
+		obj := t.Obj()
+		return obj.Pkg() == nil || obj.Pkg() == g.pkg || obj.Exported()
+	case *types.Pointer:
+		return g.nameable(t.Elem())
+	case *types.Slice:
+		return g.nameable(t.Elem())
+	case *types.Array:
+		return g.nameable(t.Elem())
+	case *types.Map:
+		return g.nameable(t.Key()) && g.nameable(t.Elem())
+	case *types.Chan:
+		return g.nameable(t.Elem())
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			f := t.Field(i)
+			if !f.Exported() && f.Pkg() != g.pkg || !g.nameable(f.Type()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// convert returns the expression converting terminal value expr of type t as
+// metaflector.Get does, e.g. all signed integers become int64.
+func convert(expr string, t types.Type) string {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return expr
+	}
+	var to types.BasicKind
+	switch b.Kind() {
+	case types.String:
+		to = types.String
+	case types.Bool:
+		to = types.Bool
+	case types.Float32, types.Float64:
+		to = types.Float64
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		to = types.Int64
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		to = types.Uint64
+	default:
+		return expr
+	}
+	if types.Identical(t, types.Typ[to]) {
+		return expr
+	}
+	return types.Typ[to].Name() + "(" + expr + ")"
+}
+
+// deref strips a single level of pointer from t, as EachField does.  ok is
+// false for pointers to pointers, which EachField can't handle.
+func deref(t types.Type) (types.Type, bool) {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+		_, isPtr := t.Underlying().(*types.Pointer)
+		return t, !isPtr
+	}
+	return t, true
+}
+
+// derefAll strips every level of pointer from t.
+func derefAll(t types.Type) types.Type {
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			return t
+		}
+		t = p.Elem()
+	}
+}
+
+func pointerDepth(t types.Type) int {
+	n := 0
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			return n
+		}
+		t = p.Elem()
+		n++
+	}
+}
+
+// hasFields returns true if t is a struct, or a pointer to or slice or array
+// of structs, whose fields are traversed.
+func hasFields(t types.Type) bool {
+	for {
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Struct:
+			return !types.Implements(t, textMarshaler) && !types.Implements(types.NewPointer(t), textMarshaler)
+		default:
+			return false
+		}
+	}
+}
+
+// isCustom returns true if t, or anything it points to or holds as elements,
+// implements FieldLister or FieldGetter, with either value or pointer
+// receivers.
+func isCustom(t types.Type) bool {
+	for {
+		for _, iface := range []*types.Interface{fieldLister, fieldGetter} {
+			if types.Implements(t, iface) || types.Implements(types.NewPointer(t), iface) {
+				return true
+			}
+		}
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		default:
+			return false
+		}
+	}
+}
+
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+func isInterface(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Interface)
+	return ok
+}
+
+// isObject returns true if m is a generic object, e.g. map[string]interface{}.
+func isObject(m *types.Map) bool {
+	if !isInterface(m.Elem()) {
+		return false
+	}
+	if isInterface(m.Key()) {
+		return true
+	}
+	b, ok := m.Key().Underlying().(*types.Basic)
+	return ok && b.Kind() == types.String
+}
+
+// isTerminalBasic returns true for the basic kinds EachField reports.
+func isTerminalBasic(b *types.Basic) bool {
+	switch b.Kind() {
+	case types.String, types.Bool, types.Float32, types.Float64,
+		types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		return true
+	}
+	return false
+}
+
+// plainName returns true if name can follow a slice in a path without being
+// taken for an index or wildcard.
+func plainName(name string) bool {
+	if name == "*" || name == "**" || name == "-" {
+		return false
+	}
+	_, err := strconv.Atoi(name)
+	return err != nil
+}
+
+func exportedName(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
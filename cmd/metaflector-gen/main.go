@@ -0,0 +1,147 @@
+// Command metaflector-gen writes reflection-free implementations of
+// TerminalFields, Get and Set for struct types, which the metaflector package
+// detects (via the StaticFields, StaticGetter and StaticSetter interfaces) and
+// uses in place of reflection.
+//
+// It's intended to be run by go generate, e.g.
+//
+//	//go:generate metaflector-gen -type Config,Order
+//
+// which writes config_metaflector.go alongside the package's other files.  The
+// generated code is specific to a separator and tag name (-sep and -tag), and
+// calls made with any other configuration fall back to reflection, as do Get
+// and Set calls for paths other than the type's terminal fields (e.g. those
+// with slice indexes), and Set calls whose value isn't of the field's exact
+// type.  Set is only generated for paths which don't pass through slices or
+// arrays.  Types containing generic map[string]interface{} or []interface{}
+// fields, whose paths depend on their contents, don't get a TerminalFields.
+//
+// Regenerate the code whenever the types change.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma-separated list of struct type names; required")
+		output    = flag.String("output", "", "output file name; default <type>_metaflector.go")
+		sep       = flag.String("sep", ".", "path separator")
+		tagName   = flag.String("tag", "", "struct tag naming fields, e.g. json")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: metaflector-gen -type T [flags] [directory]\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	types := strings.Split(*typeNames, ",")
+	if *output == "" {
+		*output = strings.ToLower(types[0]) + "_metaflector.go"
+	}
+	if !filepath.IsAbs(*output) && filepath.Dir(*output) == "." {
+		*output = filepath.Join(dir, *output)
+	}
+
+	src, err := generate(dir, types, filepath.Base(*output), *sep, *tagName, strings.Join(os.Args[1:], " "))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "metaflector-gen: %s\n", err)
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "metaflector-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the source of the file named output, implementing the
+// accessors for the named types of the package in dir.  Test files are
+// included when output is one.
+func generate(dir string, typeNames []string, output string, sep string, tagName string, args string) ([]byte, error) {
+	pkg, err := load(dir, output)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newGenerator(pkg, sep, tagName)
+	for _, name := range typeNames {
+		obj := pkg.Scope().Lookup(name)
+		if obj == nil {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkg.Name())
+		}
+		named, ok := obj.Type().(*types.Named)
+		if _, isStruct := obj.Type().Underlying().(*types.Struct); !ok || !isStruct {
+			return nil, fmt.Errorf("%s is not a struct type", name)
+		}
+		g.generate(named)
+	}
+
+	return g.source(args)
+}
+
+// load parses and type-checks the package in dir, less the output file (which
+// may be stale).
+func load(dir string, output string) (*types.Package, error) {
+	bp, err := build.Default.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	names := bp.GoFiles
+	if strings.HasSuffix(output, "_test.go") {
+		names = append(names[:len(names):len(names)], bp.TestGoFiles...)
+	}
+
+	var (
+		fset  = token.NewFileSet()
+		files = []*ast.File{}
+	)
+	for _, name := range names {
+		if name == output {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	var errs []error
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		// Carry on past errors, e.g. references to previously generated code.
+		Error: func(err error) { errs = append(errs, err) },
+	}
+	pkg, _ := conf.Check(bp.ImportPath, fset, files, nil)
+	if pkg == nil || pkg.Scope() == nil {
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		return nil, errors.New("failed to type-check package")
+	}
+	return pkg, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// TestGenerate checks that the generated code for the metaflector package's
+// tests is up to date, as it's what they verify against reflection.
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		args    string
+		types   []string
+		sep     string
+		tagName string
+		output  string
+	}{
+		{
+			args:   "-type StaticOrder,StaticDoc,StaticOuter -output static_gen_test.go",
+			types:  []string{"StaticOrder", "StaticDoc", "StaticOuter"},
+			sep:    ".",
+			output: "static_gen_test.go",
+		},
+		{
+			args:    "-type StaticTagged -sep / -tag json -output static_tagged_gen_test.go",
+			types:   []string{"StaticTagged"},
+			sep:     "/",
+			tagName: "json",
+			output:  "static_tagged_gen_test.go",
+		},
+	}
+
+	for i, test := range tests {
+		expected, err := ioutil.ReadFile("../../" + test.output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := generate("../..", test.types, test.output, test.sep, test.tagName, test.args)
+		if err != nil {
+			t.Fatalf("[i=%v] Unexpected error: %s", i, err)
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("[i=%v] Expected %s to match the generated code; run go generate", i, test.output)
+		}
+	}
+}
+
+func TestGenerateCustomFields(t *testing.T) {
+	src, err := generate("../..", []string{"StaticOuter"}, "x_test.go", ".", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(src), "MetaflectorTerminalFields") {
+		t.Errorf("Expected no TerminalFields for a type holding a FieldLister")
+	}
+	if strings.Contains(string(src), `"P.`) {
+		t.Errorf("Expected no accessors beneath a FieldLister")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		types []string
+		err   string
+	}{
+		{types: []string{"Missing"}, err: "type Missing not found"},
+		{types: []string{"StaticLevel"}, err: "StaticLevel is not a struct type"},
+	}
+
+	for i, test := range tests {
+		_, err := generate("../..", test.types, "x_test.go", ".", "", "")
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("[i=%v] Expected error=%q but actual=%v", i, test.err, err)
+		}
+	}
+}
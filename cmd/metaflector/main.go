@@ -0,0 +1,239 @@
+// Command metaflector queries JSON and YAML documents read from stdin using
+// the same paths as the metaflector package.
+//
+// Usage:
+//
+//	metaflector [flags] paths
+//	metaflector [flags] get <path>
+//	metaflector [flags] filter <expr>
+//	metaflector [flags] table <path>...
+//
+// paths lists the path of every terminal value in the document, without
+// slice indexes, using metaflector.TerminalFields.
+//
+// get prints the value at a path, fanning out over arrays, e.g.
+// "items.metadata.name" or `items[0].labels["example.com/owner"]`.  Strings
+// are printed as-is and anything else as JSON.
+//
+// filter prints the elements of a top-level array (or the document itself)
+// matching a JSONPath filter expression, as accepted by metaflector.Query
+// within [?(...)], e.g. '@.status.phase=="Running" && @.spec.replicas>2'.
+//
+// table prints a column for each path and a row for each element of a
+// top-level array (or the document itself).
+//
+// Input is detected as JSON or YAML unless -format says otherwise.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gigawattio/metaflector"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var errUsage = errors.New("usage error")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if err == errUsage {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "metaflector: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	fs := flag.NewFlagSet("metaflector", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		sep    = fs.String("sep", ".", "path separator")
+		format = fs.String("format", "auto", "input format: auto, json or yaml")
+	)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "Usage:\n"+
+			"  metaflector [flags] paths\n"+
+			"  metaflector [flags] get <path>\n"+
+			"  metaflector [flags] filter <expr>\n"+
+			"  metaflector [flags] table <path>...\n\n"+
+			"Flags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	cmd, args := args[0], args[1:]
+
+	switch {
+	case cmd == "paths" && len(args) == 0,
+		cmd == "get" && len(args) == 1,
+		cmd == "filter" && len(args) == 1,
+		cmd == "table" && len(args) > 0:
+	default:
+		fs.Usage()
+		return errUsage
+	}
+
+	doc, err := decode(stdin, *format)
+	if err != nil {
+		return err
+	}
+
+	r := metaflector.New(metaflector.WithSeparator(*sep))
+
+	switch cmd {
+	case "paths":
+		for _, path := range r.TerminalFields(doc) {
+			fmt.Fprintln(stdout, path)
+		}
+		return nil
+
+	case "get":
+		if len(r.GetWithPaths(doc, args[0])) == 0 {
+			return &metaflector.PathError{Path: args[0], Err: metaflector.ErrNotFound}
+		}
+		return printValue(stdout, r.Get(doc, args[0]))
+
+	case "filter":
+		matches, err := r.Query(rows(doc), "$[?("+args[0]+")]")
+		if err != nil {
+			return err
+		}
+		values := make([]interface{}, 0, len(matches))
+		for _, m := range matches {
+			values = append(values, m.Value)
+		}
+		return printValue(stdout, values)
+
+	default:
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(args, "\t"))
+		for _, row := range rows(doc) {
+			cells := make([]string, len(args))
+			for i, path := range args {
+				cells[i] = cell(r.Get(row, path))
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// decode reads a JSON or YAML document.  JSON numbers are kept as json.Number,
+// and YAML mappings are converted to map[string]interface{} so that they look
+// the same as decoded JSON.
+func decode(in io.Reader, format string) (interface{}, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "auto" {
+		format = "yaml"
+		if json.Valid(data) {
+			format = "json"
+		}
+	}
+
+	var doc interface{}
+	switch format {
+	case "json":
+		// Keep numbers as written, as float64 can't hold large integer IDs.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decoding JSON: %s", err)
+		}
+		if err := dec.Decode(&struct{}{}); err != io.EOF {
+			return nil, errors.New("decoding JSON: unexpected data after document")
+		}
+		return doc, nil
+
+	case "yaml":
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("decoding YAML: %s", err)
+		}
+		return normalize(doc), nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// normalize replaces the map[interface{}]interface{} values produced by the
+// YAML decoder with map[string]interface{}.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalize(value)
+		}
+		return m
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = normalize(elem)
+		}
+	}
+	return v
+}
+
+// rows returns the elements of doc if it's an array, or else doc alone.
+func rows(doc interface{}) []interface{} {
+	if elems, ok := doc.([]interface{}); ok {
+		return elems
+	}
+	return []interface{}{doc}
+}
+
+func printValue(out io.Writer, v interface{}) error {
+	if s, ok := v.(string); ok {
+		_, err := fmt.Fprintln(out, s)
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
+
+// cell formats v for a table, joining the values fanned out over arrays with
+// commas.
+func cell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		cells := make([]string, len(v))
+		for i, elem := range v {
+			cells[i] = cell(elem)
+		}
+		return strings.Join(cells, ",")
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSpace(buf.String())
+}
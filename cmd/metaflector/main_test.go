@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const podsJSON = `[
+	{"metadata": {"name": "web-0", "labels": {"app": "web", "example.com/tier": "front"}}, "spec": {"replicas": 3, "ports": [80, 443]}, "status": {"phase": "Running"}},
+	{"metadata": {"name": "db-0", "labels": {"app": "db"}}, "spec": {"replicas": 1}, "status": {"phase": "Pending"}}
+]`
+
+const podYAML = `
+metadata:
+  name: web-0
+  labels:
+    app: web
+spec:
+  replicas: 3
+  containers:
+  - name: nginx
+    image: nginx:1.25
+  - name: sidecar
+    image: envoy:1.28
+`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		args     []string
+		stdin    string
+		expected string
+		err      string
+	}{
+		{
+			args:  []string{"paths"},
+			stdin: podsJSON,
+			expected: `metadata.labels.app
+metadata.labels["example.com/tier"]
+metadata.name
+spec.ports
+spec.replicas
+status.phase
+`,
+		},
+		{
+			args:  []string{"paths"},
+			stdin: podYAML,
+			expected: `metadata.labels.app
+metadata.name
+spec.containers.image
+spec.containers.name
+spec.replicas
+`,
+		},
+		{
+			args:     []string{"-sep", "/", "paths"},
+			stdin:    `{"a": {"b.c": 1}}`,
+			expected: "a/b.c\n",
+		},
+		{
+			args:     []string{"get", "metadata.name"},
+			stdin:    podsJSON,
+			expected: "[\n  \"web-0\",\n  \"db-0\"\n]\n",
+		},
+		{
+			args:     []string{"get", `[0].metadata.labels["example.com/tier"]`},
+			stdin:    podsJSON,
+			expected: "front\n",
+		},
+		{
+			args:     []string{"get", "spec.containers[1]"},
+			stdin:    podYAML,
+			expected: "{\n  \"image\": \"envoy:1.28\",\n  \"name\": \"sidecar\"\n}\n",
+		},
+		{
+			args:  []string{"get", "spec.nope"},
+			stdin: podYAML,
+			err:   "spec.nope: path not found",
+		},
+		{
+			args:     []string{"filter", `@.status.phase=="Running" && @.spec.replicas>2`},
+			stdin:    podsJSON,
+			expected: "[\n  {\n    \"metadata\": {\n      \"labels\": {\n        \"app\": \"web\",\n        \"example.com/tier\": \"front\"\n      },\n      \"name\": \"web-0\"\n    },\n    \"spec\": {\n      \"ports\": [\n        80,\n        443\n      ],\n      \"replicas\": 3\n    },\n    \"status\": {\n      \"phase\": \"Running\"\n    }\n  }\n]\n",
+		},
+		{
+			args:     []string{"filter", `@.spec.replicas>5`},
+			stdin:    podYAML,
+			expected: "[]\n",
+		},
+		{
+			args:  []string{"filter", `@.spec.replicas>`},
+			stdin: podsJSON,
+			err:   "invalid JSONPath expression",
+		},
+		{
+			args:  []string{"table", "metadata.name", "spec.replicas", "spec.ports", "status.phase"},
+			stdin: podsJSON,
+			expected: `metadata.name  spec.replicas  spec.ports  status.phase
+web-0          3              80,443      Running
+db-0           1                          Pending
+`,
+		},
+		{
+			args:  []string{"table", "metadata.name", "spec.containers.image"},
+			stdin: podYAML,
+			expected: `metadata.name  spec.containers.image
+web-0          nginx:1.25,envoy:1.28
+`,
+		},
+		{
+			args:     []string{"get", "id"},
+			stdin:    `[{"id": 9007199254740993}, {"id": 1.50}]`,
+			expected: "[\n  9007199254740993,\n  1.50\n]\n",
+		},
+		{
+			args:     []string{"table", "id"},
+			stdin:    `{"id": 12345678901234567890}`,
+			expected: "id\n12345678901234567890\n",
+		},
+		{
+			args:  []string{"-format", "json", "paths"},
+			stdin: podYAML,
+			err:   "decoding JSON",
+		},
+		{
+			args:  []string{"-format", "json", "paths"},
+			stdin: `{"a": 1} {"b": 2}`,
+			err:   "unexpected data after document",
+		},
+		{
+			args: []string{"get"},
+			err:  "usage error",
+		},
+		{
+			args: []string{"frobnicate"},
+			err:  "usage error",
+		},
+	}
+
+	for i, test := range tests {
+		var stdout, stderr bytes.Buffer
+		err := run(test.args, strings.NewReader(test.stdin), &stdout, &stderr)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("[i=%v] Expected error containing %q but actual=%v", i, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[i=%v] Unexpected error: %s", i, err)
+			continue
+		}
+		if actual := stdout.String(); actual != test.expected {
+			t.Errorf("[i=%v] Expected output:\n%s\nbut actual:\n%s", i, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package metaflector
+
+import (
+	"reflect"
+)
+
+// FieldLister is implemented by types which supply their own child fields in
+// place of those found by reflection, e.g. lazy-loading proxies whose real
+// structure lives elsewhere.  EachField (and so TerminalFields) reports the
+// listed fields, taking their values from FieldGetter when implemented, or
+// else from the struct fields of the same names.
+type FieldLister interface {
+	// MetaflectorFields returns the names of the type's fields, in
+	// declaration order.
+	MetaflectorFields() []string
+}
+
+// FieldGetter is implemented by types which supply the values of their own
+// child fields.  EachField, Get, GetWithPaths and Query consult it before
+// falling back to reflection.
+type FieldGetter interface {
+	// MetaflectorField returns the value of the named field, or false if the
+	// type has no such field of its own.
+	MetaflectorField(name string) (value interface{}, ok bool)
+}
+
+var (
+	fieldListerType = reflect.TypeOf((*FieldLister)(nil)).Elem()
+	fieldGetterType = reflect.TypeOf((*FieldGetter)(nil)).Elem()
+)
+
+// customFields returns the FieldLister and FieldGetter implemented by v, or by
+// whatever it points to, if any.  Nil pointers aren't consulted, so methods
+// with pointer receivers are only found when reached through a pointer.
+func customFields(v reflect.Value) (lister FieldLister, getter FieldGetter) {
+	for v.IsValid() {
+		if v.CanInterface() && !isNil(v) {
+			if lister == nil && v.Type().Implements(fieldListerType) {
+				lister = v.Interface().(FieldLister)
+			}
+			if getter == nil && v.Type().Implements(fieldGetterType) {
+				getter = v.Interface().(FieldGetter)
+			}
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface || v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+	return
+}
+
+// customField returns the value of the named field of v according to its
+// FieldGetter, or the struct field of that name for a FieldLister without one.
+func (r *Reflector) customField(v reflect.Value, getter FieldGetter, name string, o *options) (reflect.Value, bool) {
+	if getter != nil {
+		value, ok := getter.MetaflectorField(name)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(value), true
+	}
+	field := r.fieldByName(indirect(v), name, o.tagName)
+	return field, field.IsValid() && field.CanInterface()
+}
+
+// eachCustomField invokes fn for each of the fields supplied by lister.
+func (r *Reflector) eachCustomField(v reflect.Value, lister FieldLister, getter FieldGetter, o *options, fn IterFunc) {
+	for _, name := range lister.MetaflectorFields() {
+		field, ok := r.customField(v, getter, name, o)
+		if !ok {
+			continue
+		}
+		if !field.IsValid() {
+			// Nils are reported as they are in generic objects.
+			fn(nil, quoteComponent(name, o.sep()), reflect.Interface)
+			continue
+		}
+		if o.excludesType(field.Type()) {
+			continue
+		}
+		r.eachFieldValue(field, quoteComponent(name, o.sep()), o, fn)
+	}
+}
+
+// isCustom returns true if obj supplies its own fields.
+func isCustom(obj interface{}) bool {
+	lister, _ := customFields(reflect.ValueOf(obj))
+	return lister != nil
+}
+
+// firstCustom returns the first non-nil element of the slice or array list if
+// it supplies its own fields.  Unlike ResolveUnderlying, pointers aren't
+// resolved, so methods with pointer receivers are still found.
+func firstCustom(list reflect.Value) (interface{}, bool) {
+	for i := 0; i < list.Len(); i++ {
+		if ele := list.Index(i); !isNil(ele) {
+			return ele.Interface(), isCustom(ele.Interface())
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,169 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+)
+
+// lazyUser loads its fields on demand, so has no structure of its own for
+// reflection to find.
+type lazyUser struct {
+	loads int
+	data  map[string]interface{}
+}
+
+func (u *lazyUser) MetaflectorFields() []string {
+	return []string{"Name", "Address", "Tags", "Manager", "Team"}
+}
+
+func (u *lazyUser) MetaflectorField(name string) (interface{}, bool) {
+	u.loads++
+	value, ok := u.data[name]
+	return value, ok
+}
+
+// listedReport hides and reorders its fields.
+type listedReport struct {
+	Secret string
+	Title  string
+	Count  int
+}
+
+func (listedReport) MetaflectorFields() []string {
+	return []string{"Title", "Count", "Missing"}
+}
+
+// rect has a virtual field in addition to its real ones.
+type rect struct {
+	W, H int
+}
+
+func (r rect) MetaflectorField(name string) (interface{}, bool) {
+	if name == "Area" {
+		return r.W * r.H, true
+	}
+	return nil, false
+}
+
+func newLazyUser() *lazyUser {
+	return &lazyUser{data: map[string]interface{}{
+		"Name":    "ann",
+		"Address": Content{Key: "city", Value: "Oslo"},
+		"Tags":    []string{"admin"},
+		"Manager": nil,
+		"Team": &lazyUser{data: map[string]interface{}{
+			"Name": "ops",
+		}},
+	}}
+}
+
+func TestCustomTerminalFields(t *testing.T) {
+	type holder struct {
+		Users  []*lazyUser
+		Report listedReport
+		Rect   rect
+	}
+
+	tests := []struct {
+		obj      interface{}
+		opts     []Option
+		expected []string
+	}{
+		{
+			obj:      newLazyUser(),
+			expected: []string{"Address.Key", "Address.Value", "Address.Version", "Manager", "Name", "Tags", "Team.Name"},
+		},
+		{
+			obj:      newLazyUser(),
+			opts:     []Option{WithDeclarationOrder()},
+			expected: []string{"Name", "Address.Key", "Address.Value", "Address.Version", "Tags", "Manager", "Team.Name"},
+		},
+		{
+			obj:      newLazyUser(),
+			opts:     []Option{WithExcludedTypes(reflect.TypeOf(Content{})), WithMaxDepth(1)},
+			expected: []string{"Manager", "Name", "Tags"},
+		},
+		{
+			obj:      listedReport{Secret: "x"},
+			opts:     []Option{WithDeclarationOrder()},
+			expected: []string{"Title", "Count"},
+		},
+		{
+			obj:      rect{},
+			expected: []string{"H", "W"},
+		},
+		{
+			obj:      holder{Users: []*lazyUser{nil, newLazyUser()}},
+			opts:     []Option{WithFilter("Users.*", "Report.*")},
+			expected: []string{"Report.Count", "Report.Title", "Users.Manager", "Users.Name", "Users.Tags"},
+		},
+		{
+			obj:      &holder{},
+			opts:     []Option{WithFilter("Users.*")},
+			expected: []string{},
+		},
+	}
+
+	for i, test := range tests {
+		if actual := TerminalFields(test.obj, test.opts...); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("[i=%v] Expected fields=%v but actual=%v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestCustomEachField(t *testing.T) {
+	user := newLazyUser()
+	actual := map[string]reflect.Kind{}
+	if ok := EachField(user, func(_ interface{}, name string, kind reflect.Kind) { actual[name] = kind }); !ok {
+		t.Fatalf("Expected EachField to accept a FieldLister")
+	}
+
+	expected := map[string]reflect.Kind{
+		"Name":    reflect.String,
+		"Address": reflect.Struct,
+		"Tags":    reflect.Slice,
+		"Manager": reflect.Interface,
+		"Team":    reflect.Ptr,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%v but actual=%v", expected, actual)
+	}
+	if user.loads != len(expected) {
+		t.Errorf("Expected each field to be loaded once but actual loads=%v", user.loads)
+	}
+}
+
+func TestCustomGet(t *testing.T) {
+	var (
+		user  = newLazyUser()
+		users = []*lazyUser{newLazyUser(), nil, newLazyUser()}
+	)
+
+	tests := []struct {
+		obj      interface{}
+		path     string
+		expected interface{}
+	}{
+		{obj: user, path: "Name", expected: "ann"},
+		{obj: user, path: "Address.Value", expected: "Oslo"},
+		{obj: user, path: "Team.Name", expected: "ops"},
+		{obj: user, path: "Team.Tags", expected: nil},
+		{obj: user, path: "Manager", expected: nil},
+		{obj: user, path: "Missing", expected: nil},
+		{obj: users, path: "Team.Name", expected: []interface{}{"ops", nil, "ops"}},
+		{obj: rect{W: 2, H: 3}, path: "Area", expected: int64(6)},
+		{obj: &rect{W: 2, H: 3}, path: "W", expected: int64(2)},
+		{obj: listedReport{Secret: "x"}, path: "Secret", expected: "x"},
+	}
+
+	for i, test := range tests {
+		if actual := Get(test.obj, test.path); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("[i=%v] Expected value=%#v but actual=%#v", i, test.expected, actual)
+		}
+	}
+
+	expected := []Match{{Path: "Name", Value: "ann"}, {Path: "Team.Name", Value: "ops"}}
+	if actual := GetWithPaths(user, "**.Name"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected matches=%v but actual=%v", expected, actual)
+	}
+}
@@ -0,0 +1,145 @@
+package metaflector
+
+import (
+	"reflect"
+)
+
+// DeepCopy returns a copy of obj which shares no pointers, slices or maps
+// with the original, walking nested structs, slices, arrays, maps,
+// interfaces and pointers as EachField does.
+//
+// Sharing within the graph is preserved: two pointers (or maps, or slices of
+// the same length) referring to the same thing in obj refer to the same copy
+// in the result, so cyclic structures are copied faithfully too.  Pointers
+// into the interior of other values (e.g. to a struct field or slice element)
+// are copied separately from the value containing them.
+//
+// Unexported fields can't be set via reflection and so are copied shallowly,
+// as are functions, channels and map keys.
+func DeepCopy(obj interface{}) interface{} {
+	if obj == nil {
+		return nil
+	}
+	c := &copier{
+		pointers: map[ref]reflect.Value{},
+		maps:     map[ref]reflect.Value{},
+		slices:   map[sliceRef]reflect.Value{},
+	}
+	return c.copy(reflect.ValueOf(obj)).Interface()
+}
+
+// ref identifies a pointer or map by address and type, as a pointer to a
+// struct and a pointer to its first field share the same address.
+type ref struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+type sliceRef struct {
+	ref
+	len int
+}
+
+// copier tracks the copies made so far, so that shared references are copied
+// only once.
+type copier struct {
+	pointers map[ref]reflect.Value
+	maps     map[ref]reflect.Value
+	slices   map[sliceRef]reflect.Value
+}
+
+func (c *copier) copy(src reflect.Value) reflect.Value {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return src
+		}
+		key := ref{addr: src.Pointer(), typ: src.Type()}
+		if dst, ok := c.pointers[key]; ok {
+			return dst
+		}
+		// Register the copy before descending, so cycles resolve to it.
+		dst := reflect.New(src.Type().Elem())
+		c.pointers[key] = dst
+		dst.Elem().Set(c.copy(src.Elem()))
+		return dst
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(c.copy(src.Elem()))
+		return dst
+
+	case reflect.Struct:
+		dst := reflect.New(src.Type()).Elem()
+		// Start from a shallow copy, carrying over unexported fields.
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if field := dst.Field(i); field.CanSet() {
+				field.Set(c.copy(src.Field(i)))
+			}
+		}
+		return dst
+
+	case reflect.Map:
+		if src.IsNil() {
+			return src
+		}
+		key := ref{addr: src.Pointer(), typ: src.Type()}
+		if dst, ok := c.maps[key]; ok {
+			return dst
+		}
+		dst := reflect.MakeMap(src.Type())
+		c.maps[key] = dst
+		for _, k := range src.MapKeys() {
+			dst.SetMapIndex(k, c.copy(src.MapIndex(k)))
+		}
+		return dst
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return src
+		}
+		key := sliceRef{ref: ref{addr: src.Pointer(), typ: src.Type()}, len: src.Len()}
+		if dst, ok := c.slices[key]; ok {
+			return dst
+		}
+		dst := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		c.slices[key] = dst
+		if isFlat(src.Type().Elem()) {
+			reflect.Copy(dst, src)
+			return dst
+		}
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return dst
+
+	case reflect.Array:
+		dst := reflect.New(src.Type()).Elem()
+		if isFlat(src.Type().Elem()) {
+			dst.Set(src)
+			return dst
+		}
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return dst
+	}
+
+	return src
+}
+
+// isFlat returns true for types whose values contain no references, and so
+// can be copied by assignment.
+func isFlat(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		return true
+	case reflect.Array:
+		return isFlat(t.Elem())
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	contents := []Content{{Key: "c0"}, {Key: "c1"}}
+	obj := &Foo{
+		Bar: Bar{
+			Baz: Baz{
+				Name:              "baz",
+				Contents:          contents,
+				ContentPtrs:       []*Content{&contents[0], nil},
+				PtrContentPtrPtrs: &[]**Content{&notHotdogPtr, &notHotdogPtr},
+				Map:               map[string]string{"a": "1"},
+				PtrA:              &uEight,
+				hiddenString:      "hidden",
+			},
+		},
+		Contents: contents,
+	}
+	obj.StructPtr = &Bar{Stock: "ptr"}
+
+	cp := DeepCopy(obj).(*Foo)
+
+	if !reflect.DeepEqual(cp, obj) {
+		t.Fatalf("Expected copy=%+v to equal original=%+v", cp, obj)
+	}
+	if expected, actual := "hidden", cp.Bar.Baz.hiddenString; actual != expected {
+		t.Errorf("Expected unexported field=%q but actual=%q", expected, actual)
+	}
+
+	// Nothing is shared with the original.
+	if cp == obj || cp.StructPtr == obj.StructPtr || cp.Bar.Baz.PtrA == obj.Bar.Baz.PtrA || *(*cp.Bar.Baz.PtrContentPtrPtrs)[0] == notHotdogPtr {
+		t.Errorf("Expected pointers to be copied")
+	}
+	cp.Bar.Baz.Contents[0].Key = "changed"
+	cp.Bar.Baz.Map["a"] = "changed"
+	(**(*cp.Bar.Baz.PtrContentPtrPtrs)[0]).Key = "changed"
+	if obj.Bar.Baz.Contents[0].Key != "c0" || obj.Bar.Baz.Map["a"] != "1" || notHotdogPtr.Key != "not" {
+		t.Errorf("Expected original to be unaffected by changes to the copy")
+	}
+
+	// Sharing within the graph is preserved.
+	if (*cp.Bar.Baz.PtrContentPtrPtrs)[0] != (*cp.Bar.Baz.PtrContentPtrPtrs)[1] {
+		t.Errorf("Expected shared pointers to remain shared")
+	}
+	if &cp.Contents[0] != &cp.Bar.Baz.Contents[0] {
+		t.Errorf("Expected shared slices to remain shared")
+	}
+
+	// Values which aren't pointers are copied too.
+	m := map[string]interface{}{
+		"list": []interface{}{1, "two", map[string]interface{}{"three": 3}},
+		"arr":  [2]*Content{{Key: "a"}, nil},
+	}
+	mcp := DeepCopy(m).(map[string]interface{})
+	if !reflect.DeepEqual(mcp, m) {
+		t.Fatalf("Expected copy=%v to equal original=%v", mcp, m)
+	}
+	mcp["list"].([]interface{})[2].(map[string]interface{})["three"] = 4
+	mcp["arr"].([2]*Content)[0].Key = "b"
+	if m["list"].([]interface{})[2].(map[string]interface{})["three"] != 3 || m["arr"].([2]*Content)[0].Key != "a" {
+		t.Errorf("Expected original map to be unaffected by changes to the copy")
+	}
+
+	if DeepCopy(nil) != nil {
+		t.Errorf("Expected nil copy of nil")
+	}
+	if expected, actual := 5, DeepCopy(5); actual != expected {
+		t.Errorf("Expected copy=%v but actual=%v", expected, actual)
+	}
+}
+
+func TestDeepCopyCycle(t *testing.T) {
+	type Node struct {
+		Name     string
+		Parent   *Node
+		Children []*Node
+		Index    map[string]*Node
+	}
+
+	root := &Node{Name: "root", Index: map[string]*Node{}}
+	for _, name := range []string{"a", "b"} {
+		child := &Node{Name: name, Parent: root, Index: root.Index}
+		root.Children = append(root.Children, child)
+		root.Index[name] = child
+	}
+	root.Index["root"] = root
+
+	cp := DeepCopy(root).(*Node)
+
+	if cp == root || cp.Children[0] == root.Children[0] {
+		t.Fatalf("Expected nodes to be copied")
+	}
+	if cp.Children[0].Parent != cp || cp.Children[1].Parent != cp {
+		t.Errorf("Expected children to refer to the copied root")
+	}
+	if cp.Index["a"] != cp.Children[0] || cp.Index["root"] != cp {
+		t.Errorf("Expected index to refer to copied nodes")
+	}
+	if reflect.ValueOf(cp.Children[0].Index).Pointer() != reflect.ValueOf(cp.Index).Pointer() {
+		t.Errorf("Expected shared maps to remain shared")
+	}
+	if reflect.ValueOf(cp.Index).Pointer() == reflect.ValueOf(root.Index).Pointer() {
+		t.Errorf("Expected index map to be copied")
+	}
+}
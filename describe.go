@@ -0,0 +1,153 @@
+package metaflector
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Describe returns an indented tree of the fields of obj's type, which may
+// also be given directly as a reflect.Type, suitable for printing as e.g.
+// `--describe` output.  Each line shows a field's name, its Go type, its kind
+// and its struct tag:
+//
+//	*main.Config  *struct
+//	  Name        string             string     `json:"name"`
+//	  Hosts       []*main.Host       []*struct  `json:"hosts"`
+//	    Addr      string             string     `json:"addr"`
+//	    Labels    map[string]string  map        `json:"labels"`
+//	  Timeout     time.Duration      int64      `json:"timeout"`
+//
+// The kind is prefixed with "*" for each pointer, "[]" for each slice and
+// "[n]" for each array wrapping the underlying type, so e.g. *[]**Content
+// has kind "*[]**struct".
+//
+// Fields are traversed by type as EachField would, in declaration order,
+// descending into structs through pointers, slices and arrays, and honoring
+// WithTagName, WithMaxDepth, WithFilter and WithExcludedTypes.  A struct type
+// which contains itself is described only once per branch, with recursive
+// occurrences marked "(recursive)".
+func Describe(obj interface{}, opts ...Option) string {
+	return defaultReflector.Describe(obj, opts...)
+}
+
+// Describe is the Reflector equivalent of the package-level Describe.
+func (r *Reflector) Describe(obj interface{}, opts ...Option) string {
+	t, ok := obj.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(obj)
+	}
+	if t == nil {
+		return ""
+	}
+
+	var (
+		buf = &bytes.Buffer{}
+		tw  = tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+		d   = &describer{
+			r:    r,
+			o:    r.options(opts),
+			w:    tw,
+			seen: map[reflect.Type]struct{}{},
+		}
+	)
+	d.includes, d.excludes = compilePatterns(d.o.patterns, d.o.sep())
+
+	fmt.Fprintf(tw, "%v\t%v\n", t, kindOf(t))
+	if elem := indirectElem(t); elem.Kind() == reflect.Struct && !isTerminalStruct(elem) {
+		d.seen[elem] = struct{}{}
+		d.fields(elem, nil)
+	}
+
+	tw.Flush()
+
+	// Fields without tags leave the padding of the kind column behind.
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+type describer struct {
+	r                  *Reflector
+	o                  *options
+	w                  *tabwriter.Writer
+	includes, excludes []pattern
+	seen               map[reflect.Type]struct{} // Struct types on the current branch.
+}
+
+// fields writes a line for each field of struct type t, followed by the
+// fields beneath it.  components holds the path of t.
+func (d *describer) fields(t reflect.Type, components []string) {
+	indent := strings.Repeat("  ", len(components)+1)
+
+	for _, f := range d.r.fields(t, d.o.tagName) {
+		if d.o.excludesType(f.typ) {
+			continue
+		}
+
+		var (
+			childComponents = append(components[:len(components):len(components)], f.name)
+			depth           = len(childComponents)
+			terminal        = !hasFields(f.typ)
+		)
+
+		if d.o.maxDepth > 0 && depth > d.o.maxDepth {
+			continue
+		}
+		if !allowed(childComponents, terminal, d.includes, d.excludes) {
+			continue
+		}
+
+		var (
+			elem = indirectElem(f.typ)
+			tag  = string(t.Field(f.index).Tag)
+		)
+		if tag != "" {
+			tag = "`" + tag + "`"
+		}
+		_, recursive := d.seen[elem]
+		if recursive {
+			tag = strings.TrimSpace(tag + " (recursive)")
+		}
+		fmt.Fprintf(d.w, "%v%v\t%v\t%v\t%v\n", indent, f.name, f.typ, kindOf(f.typ), tag)
+
+		if terminal || recursive || d.o.maxDepth > 0 && depth == d.o.maxDepth {
+			continue
+		}
+		d.seen[elem] = struct{}{}
+		d.fields(elem, childComponents)
+		delete(d.seen, elem)
+	}
+}
+
+// kindOf returns the kind of the type underlying t, prefixed with a marker for
+// each pointer, slice or array wrapping it.
+func kindOf(t reflect.Type) string {
+	prefix := ""
+	for {
+		switch t.Kind() {
+		case reflect.Ptr:
+			prefix += "*"
+		case reflect.Slice:
+			prefix += "[]"
+		case reflect.Array:
+			prefix += fmt.Sprintf("[%v]", t.Len())
+		default:
+			return prefix + t.Kind().String()
+		}
+		t = t.Elem()
+	}
+}
+
+// indirectElem returns the type underlying any pointers, slices and arrays
+// wrapping t.
+func indirectElem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,87 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	type (
+		Host struct {
+			Addr   string            `json:"addr"`
+			Labels map[string]string `json:"labels,omitempty"`
+		}
+
+		Config struct {
+			Name    string        `json:"name" usage:"service name"`
+			Hosts   []*Host       `json:"hosts"`
+			Backup  *[2]Host      `json:"backup"`
+			Started time.Time     `json:"started"`
+			Timeout time.Duration `json:"timeout"`
+			hidden  string
+		}
+
+		Tree struct {
+			Value    int
+			Children []*Tree
+		}
+	)
+
+	tests := []struct {
+		obj      interface{}
+		opts     []Option
+		expected string
+	}{
+		{
+			obj: &Config{},
+			expected: "*metaflector.Config  *struct\n" +
+				"  Name               string                string      `json:\"name\" usage:\"service name\"`\n" +
+				"  Hosts              []*metaflector.Host   []*struct   `json:\"hosts\"`\n" +
+				"    Addr             string                string      `json:\"addr\"`\n" +
+				"    Labels           map[string]string     map         `json:\"labels,omitempty\"`\n" +
+				"  Backup             *[2]metaflector.Host  *[2]struct  `json:\"backup\"`\n" +
+				"    Addr             string                string      `json:\"addr\"`\n" +
+				"    Labels           map[string]string     map         `json:\"labels,omitempty\"`\n" +
+				"  Started            time.Time             struct      `json:\"started\"`\n" +
+				"  Timeout            time.Duration         int64       `json:\"timeout\"`\n",
+		},
+		{
+			obj:  reflect.TypeOf(Config{}),
+			opts: []Option{WithTagName("json"), WithFilter("hosts.*", "!hosts.labels"), WithExcludedTypes(reflect.TypeOf(time.Time{}))},
+			expected: "metaflector.Config  struct\n" +
+				"  hosts             []*metaflector.Host  []*struct  `json:\"hosts\"`\n" +
+				"    addr            string               string     `json:\"addr\"`\n",
+		},
+		{
+			obj:  Config{},
+			opts: []Option{WithMaxDepth(1)},
+			expected: "metaflector.Config  struct\n" +
+				"  Name              string                string      `json:\"name\" usage:\"service name\"`\n" +
+				"  Hosts             []*metaflector.Host   []*struct   `json:\"hosts\"`\n" +
+				"  Backup            *[2]metaflector.Host  *[2]struct  `json:\"backup\"`\n" +
+				"  Started           time.Time             struct      `json:\"started\"`\n" +
+				"  Timeout           time.Duration         int64       `json:\"timeout\"`\n",
+		},
+		{
+			obj: Tree{},
+			expected: "metaflector.Tree  struct\n" +
+				"  Value           int                  int\n" +
+				"  Children        []*metaflector.Tree  []*struct  (recursive)\n",
+		},
+		{
+			obj:      []string{},
+			expected: "[]string  []string\n",
+		},
+		{
+			obj:      nil,
+			expected: "",
+		},
+	}
+
+	for i, test := range tests {
+		if actual := Describe(test.obj, test.opts...); actual != test.expected {
+			t.Errorf("[i=%v] Expected description:\n%s\nbut actual:\n%s", i, test.expected, actual)
+		}
+	}
+}
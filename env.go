@@ -0,0 +1,105 @@
+package metaflector
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// EnvVar describes an environment variable recognized by LoadEnv.
+type EnvVar struct {
+	Name string       // e.g. "APP_BAR_BAZ_NAME".
+	Path string       // e.g. "Bar.Baz.Name".
+	Type reflect.Type // The field's type, e.g. for help text.
+}
+
+// EnvName is the default transform from a field's path components to an
+// environment variable name: the components are upper-cased and joined with
+// underscores, and any other characters which aren't ASCII letters or digits
+// are replaced with underscores, e.g. "Bar.Baz.Name" becomes "BAR_BAZ_NAME".
+func EnvName(components []string) string {
+	name := []byte(strings.ToUpper(strings.Join(components, "_")))
+	for i, c := range name {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// EnvVars lists the environment variables which LoadEnv would consult for
+// obj, in declaration order.  obj may be a struct or a pointer to one, and
+// nil pointers are expanded based on their type.
+func EnvVars(obj interface{}, prefix string, opts ...Option) []EnvVar {
+	return defaultReflector.EnvVars(obj, prefix, opts...)
+}
+
+// EnvVars is the Reflector equivalent of the package-level EnvVars.
+func (r *Reflector) EnvVars(obj interface{}, prefix string, opts ...Option) []EnvVar {
+	var (
+		o    = r.options(opts)
+		vars = []EnvVar{}
+	)
+	for _, s := range r.settings(reflect.TypeOf(obj), o) {
+		vars = append(vars, EnvVar{
+			Name: envName(prefix, s.components, o),
+			Path: s.path,
+			Type: s.field.Type,
+		})
+	}
+	return vars
+}
+
+// LoadEnv populates the terminal fields of obj, which must be a non-nil
+// pointer to a struct, from environment variables named after their paths.
+// With a prefix of "APP", the field at "Bar.Baz.Name" is read from
+// APP_BAR_BAZ_NAME; pass WithEnvName to change how names are derived.
+//
+// Fields may be strings, bools, numbers, time.Duration, types implementing
+// encoding.TextUnmarshaler, pointers to any of these, or slices of them, which
+// are split on "," (see WithDelimiter).  Nil pointers are allocated as
+// needed, and fields whose variables are unset are left untouched.
+func LoadEnv(obj interface{}, prefix string, opts ...Option) error {
+	return defaultReflector.LoadEnv(obj, prefix, opts...)
+}
+
+// LoadEnv is the Reflector equivalent of the package-level LoadEnv.
+func (r *Reflector) LoadEnv(obj interface{}, prefix string, opts ...Option) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrNotSettable
+	}
+
+	o := r.options(opts)
+
+	for _, s := range r.settings(v.Type(), o) {
+		name := envName(prefix, s.components, o)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		value, err := parseValue(s.field.Type, raw, o.delim())
+		if err != nil {
+			return &PathError{Path: s.path, Err: fmt.Errorf("%s: %v", name, err)}
+		}
+		if err := r.set(v.Elem(), s.components, value.Interface(), o); err != nil {
+			return &PathError{Path: s.path, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// envName applies the configured transform and prefix to the components.
+func envName(prefix string, components []string, o *options) string {
+	transform := o.envName
+	if transform == nil {
+		transform = EnvName
+	}
+	name := transform(components)
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimSuffix(prefix, "_") + "_" + name
+}
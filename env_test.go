@@ -0,0 +1,170 @@
+package metaflector
+
+import (
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	EnvConfig struct {
+		Name     string
+		Port     uint16
+		Debug    bool
+		Ratio    float32
+		Timeout  time.Duration
+		Hosts    []string
+		Ports    []int
+		Backoff  []time.Duration
+		Addr     net.IP
+		Limit    *int
+		Database *EnvDatabase
+		Server   EnvServer
+		Next     *EnvConfig
+		Extra    map[string]string
+		Items    []Content
+	}
+
+	EnvDatabase struct {
+		URL      string `json:"url"`
+		PoolSize int    `json:"pool_size"`
+	}
+
+	EnvServer struct {
+		Listen string
+	}
+)
+
+func setenv(t *testing.T, env map[string]string) func() {
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() {
+		for k := range env {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestEnvVars(t *testing.T) {
+	var (
+		vars  = EnvVars(&EnvConfig{}, "APP")
+		names = []string{}
+	)
+	for _, v := range vars {
+		names = append(names, v.Name+"="+v.Path)
+	}
+	expected := []string{
+		"APP_NAME=Name",
+		"APP_PORT=Port",
+		"APP_DEBUG=Debug",
+		"APP_RATIO=Ratio",
+		"APP_TIMEOUT=Timeout",
+		"APP_HOSTS=Hosts",
+		"APP_PORTS=Ports",
+		"APP_BACKOFF=Backoff",
+		"APP_ADDR=Addr",
+		"APP_LIMIT=Limit",
+		"APP_DATABASE_URL=Database.URL",
+		"APP_DATABASE_POOLSIZE=Database.PoolSize",
+		"APP_SERVER_LISTEN=Server.Listen",
+	}
+	if actual := names; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected vars=%# v but actual=%# v", expected, actual)
+	}
+	if expected, actual := reflect.TypeOf(time.Duration(0)), vars[4].Type; actual != expected {
+		t.Errorf("Expected type=%v but actual=%v", expected, actual)
+	}
+
+	// Custom transforms, tag names and filters.
+	r := New(WithTagName("json"))
+	vars = r.EnvVars(EnvConfig{}, "app_", WithFilter("Database.*"), WithEnvName(func(components []string) string {
+		return strings.Join(components, "__")
+	}))
+	names = []string{}
+	for _, v := range vars {
+		names = append(names, v.Name+"="+v.Path)
+	}
+	expected = []string{
+		"app_Database__url=Database.url",
+		"app_Database__pool_size=Database.pool_size",
+	}
+	if actual := names; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected vars=%# v but actual=%# v", expected, actual)
+	}
+
+	if expected, actual := 0, len(EnvVars("not a struct", "")); actual != expected {
+		t.Errorf("Expected %v vars for a non-struct but actual=%v", expected, actual)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	defer setenv(t, map[string]string{
+		"APP_NAME":              "svc",
+		"APP_PORT":              "0x1F90",
+		"APP_DEBUG":             "true",
+		"APP_RATIO":             "0.5",
+		"APP_TIMEOUT":           "1m30s",
+		"APP_HOSTS":             "a.example.com, b.example.com",
+		"APP_PORTS":             "",
+		"APP_BACKOFF":           "1s,2s",
+		"APP_ADDR":              "10.0.0.1",
+		"APP_LIMIT":             "7",
+		"APP_DATABASE_POOLSIZE": "4",
+	})()
+
+	cfg := &EnvConfig{
+		Name:   "default",
+		Ports:  []int{80},
+		Server: EnvServer{Listen: ":80"},
+	}
+	if err := LoadEnv(cfg, "APP"); err != nil {
+		t.Fatal(err)
+	}
+
+	limit := 7
+	expected := &EnvConfig{
+		Name:     "svc",
+		Port:     8080,
+		Debug:    true,
+		Ratio:    0.5,
+		Timeout:  90 * time.Second,
+		Hosts:    []string{"a.example.com", "b.example.com"},
+		Ports:    []int{},
+		Backoff:  []time.Duration{time.Second, 2 * time.Second},
+		Addr:     net.ParseIP("10.0.0.1"),
+		Limit:    &limit,
+		Database: &EnvDatabase{PoolSize: 4},
+		Server:   EnvServer{Listen: ":80"},
+	}
+	if actual := cfg; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected config=%+v but actual=%+v", expected, actual)
+	}
+
+	// Delimiters are configurable.
+	defer setenv(t, map[string]string{"X_PORTS": "1;2;3"})()
+	if err := LoadEnv(cfg, "X", WithDelimiter(";")); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := []int{1, 2, 3}, cfg.Ports; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected ports=%v but actual=%v", expected, actual)
+	}
+
+	defer setenv(t, map[string]string{"BAD_PORT": "70000"})()
+	err := LoadEnv(cfg, "BAD")
+	if err == nil {
+		t.Fatalf("Expected error for out of range port")
+	}
+	if pathErr, ok := err.(*PathError); !ok || pathErr.Path != "Port" || !strings.Contains(err.Error(), "BAD_PORT") {
+		t.Errorf("Expected path error naming BAD_PORT but got %v", err)
+	}
+
+	if expected, actual := ErrNotSettable, LoadEnv(EnvConfig{}, "APP"); actual != expected {
+		t.Errorf("Expected err=%v but actual=%v", expected, actual)
+	}
+}
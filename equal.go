@@ -0,0 +1,290 @@
+package metaflector
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Equal reports whether a and b are deeply equal, in the same sense as
+// reflect.DeepEqual, along with the path of the first difference found when
+// they aren't, e.g. "Bar.Baz.Contents[2].Key".  The path is empty when a and
+// b differ at the root, such as when their types differ.
+//
+// The comparison may be relaxed with WithIgnore, WithFloatTolerance and
+// WithNilEqualsEmpty.  Values whose type has an Equal method of the form
+// func(T) bool, such as time.Time, are compared with it.  Struct fields are
+// compared in declaration order and map entries in key order, so the path
+// reported is deterministic.
+func Equal(a interface{}, b interface{}, opts ...Option) (bool, string) {
+	return defaultReflector.Equal(a, b, opts...)
+}
+
+// Equal is the Reflector equivalent of the package-level Equal.
+func (r *Reflector) Equal(a interface{}, b interface{}, opts ...Option) (bool, string) {
+	o := r.options(opts)
+	ignores, _ := compilePatterns(o.ignore, o.sep())
+	c := &comparer{
+		r:       r,
+		o:       o,
+		ignores: ignores,
+		visited: map[visit]struct{}{},
+	}
+	if diff, ok := c.compare(reflect.ValueOf(a), reflect.ValueOf(b), location{}); !ok {
+		return false, diff
+	}
+	return true, ""
+}
+
+// visit records a pair of references already being compared, so that cycles
+// terminate.
+type visit struct {
+	a   uintptr
+	b   uintptr
+	typ reflect.Type
+}
+
+// location is the position of a value being compared.  plain holds the same
+// components as components, less any slice and array indexes, for matching
+// against ignore patterns written in the style of TerminalFields.
+type location struct {
+	path       string
+	components []string
+	plain      []string
+}
+
+func (l location) child(name string, sep string) location {
+	return location{
+		path:       childPath(l.path, quoteComponent(name, sep), sep),
+		components: append(l.components[:len(l.components):len(l.components)], name),
+		plain:      append(l.plain[:len(l.plain):len(l.plain)], name),
+	}
+}
+
+func (l location) index(i int, sep string) location {
+	return location{
+		path:       childPath(l.path, indexComponent(i), sep),
+		components: append(l.components[:len(l.components):len(l.components)], strconv.Itoa(i)),
+		plain:      l.plain,
+	}
+}
+
+type comparer struct {
+	r       *Reflector
+	o       *options
+	ignores []pattern
+	visited map[visit]struct{}
+}
+
+func (c *comparer) ignored(l location) bool {
+	if len(l.components) == 0 {
+		return false
+	}
+	for _, p := range c.ignores {
+		if p.Match(l.components) || p.Match(l.plain) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare returns true if a and b are equal, or else false along with the
+// path at which they differ.
+func (c *comparer) compare(a reflect.Value, b reflect.Value, l location) (string, bool) {
+	if c.ignored(l) {
+		return "", true
+	}
+
+	if !a.IsValid() || !b.IsValid() {
+		return l.path, a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return l.path, false
+	}
+
+	if equal, ok := equalMethod(a, b); ok {
+		return l.path, equal
+	}
+
+	sep := c.o.sep()
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return l.path, a.IsNil() == b.IsNil()
+		}
+		if a.Pointer() == b.Pointer() {
+			return "", true
+		}
+		if c.seen(a, b) {
+			return "", true
+		}
+		return c.compare(a.Elem(), b.Elem(), l)
+
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return l.path, a.IsNil() == b.IsNil()
+		}
+		return c.compare(a.Elem(), b.Elem(), l)
+
+	case reflect.Struct:
+		names := map[int]string{}
+		for _, f := range c.r.fields(a.Type(), c.o.tagName) {
+			names[f.index] = f.name
+		}
+		for i := 0; i < a.NumField(); i++ {
+			name, ok := names[i]
+			if !ok {
+				name = a.Type().Field(i).Name
+			}
+			if diff, ok := c.compare(a.Field(i), b.Field(i), l.child(name, sep)); !ok {
+				return diff, false
+			}
+		}
+		return "", true
+
+	case reflect.Map:
+		if a.Len() == 0 && b.Len() == 0 {
+			return l.path, c.o.nilIsEmpty || a.IsNil() == b.IsNil()
+		}
+		if a.IsNil() || b.IsNil() {
+			return l.path, false
+		}
+		if a.Pointer() == b.Pointer() || c.seen(a, b) {
+			return "", true
+		}
+		for _, k := range unionKeys(a, b) {
+			child := l.child(keyName(k), sep)
+			if diff, ok := c.compare(a.MapIndex(k), b.MapIndex(k), child); !ok {
+				return diff, false
+			}
+		}
+		return "", true
+
+	case reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return l.path, c.o.nilIsEmpty || a.IsNil() == b.IsNil()
+		}
+		if a.IsNil() || b.IsNil() {
+			return l.path, false
+		}
+		if a.Pointer() == b.Pointer() && a.Len() == b.Len() || c.seen(a, b) {
+			return "", true
+		}
+		return c.compareElements(a, b, l)
+
+	case reflect.Array:
+		return c.compareElements(a, b, l)
+
+	case reflect.Float32, reflect.Float64:
+		x, y := a.Float(), b.Float()
+		return l.path, x == y || math.Abs(x-y) <= c.o.tolerance
+
+	case reflect.Complex64, reflect.Complex128:
+		x, y := a.Complex(), b.Complex()
+		return l.path, x == y || cmplx.Abs(x-y) <= c.o.tolerance
+
+	case reflect.Bool:
+		return l.path, a.Bool() == b.Bool()
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return l.path, a.Int() == b.Int()
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return l.path, a.Uint() == b.Uint()
+
+	case reflect.String:
+		return l.path, a.String() == b.String()
+
+	case reflect.Func:
+		// As with reflect.DeepEqual, functions are only equal when both nil.
+		return l.path, a.IsNil() && b.IsNil()
+
+	case reflect.Chan, reflect.UnsafePointer:
+		return l.path, a.Pointer() == b.Pointer()
+	}
+
+	return l.path, false
+}
+
+// equalMethod compares a and b using their type's Equal method, if it has
+// one of the form func(T) bool, as time.Time does.  ok is false if no such
+// method is available.
+func equalMethod(a reflect.Value, b reflect.Value) (equal bool, ok bool) {
+	if a.Kind() == reflect.Ptr || a.Kind() == reflect.Interface || !a.CanInterface() || !b.CanInterface() {
+		return false, false
+	}
+	m := a.MethodByName("Equal")
+	if !m.IsValid() {
+		return false, false
+	}
+	if t := m.Type(); t.NumIn() != 1 || t.In(0) != a.Type() || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Bool {
+		return false, false
+	}
+	return m.Call([]reflect.Value{b})[0].Bool(), true
+}
+
+// compareElements compares slices or arrays element by element.  When one is
+// longer, the first extra element is reported as the difference.
+func (c *comparer) compareElements(a reflect.Value, b reflect.Value, l location) (string, bool) {
+	sep := c.o.sep()
+	for i := 0; i < a.Len() && i < b.Len(); i++ {
+		if diff, ok := c.compare(a.Index(i), b.Index(i), l.index(i, sep)); !ok {
+			return diff, false
+		}
+	}
+	if a.Len() != b.Len() {
+		n := a.Len()
+		if b.Len() < n {
+			n = b.Len()
+		}
+		return l.index(n, sep).path, false
+	}
+	return "", true
+}
+
+// seen records the pair of references, returning true if they were already
+// recorded.
+func (c *comparer) seen(a reflect.Value, b reflect.Value) bool {
+	v := visit{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+	if _, ok := c.visited[v]; ok {
+		return true
+	}
+	c.visited[v] = struct{}{}
+	return false
+}
+
+// unionKeys returns the keys present in either map, sorted by their string
+// representation.  Keys are told apart by the maps themselves, so distinct
+// keys sharing a representation (e.g. 1 and "1") are all kept.
+func unionKeys(a reflect.Value, b reflect.Value) []reflect.Value {
+	keys := a.MapKeys()
+	for _, k := range b.MapKeys() {
+		if !a.MapIndex(k).IsValid() {
+			keys = append(keys, k)
+		}
+	}
+	sort.Sort(byKeyName(keys))
+	return keys
+}
+
+// byKeyName sorts map keys by the path components naming them.
+type byKeyName []reflect.Value
+
+func (keys byKeyName) Len() int           { return len(keys) }
+func (keys byKeyName) Less(i, j int) bool { return keyName(keys[i]) < keyName(keys[j]) }
+func (keys byKeyName) Swap(i, j int)      { keys[i], keys[j] = keys[j], keys[i] }
+
+// keyName returns the path component used for a map key.
+func keyName(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if k.CanInterface() {
+		return fmt.Sprint(k.Interface())
+	}
+	return fmt.Sprint(k)
+}
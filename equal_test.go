@@ -0,0 +1,146 @@
+package metaflector
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEqual(t *testing.T) {
+	type Event struct {
+		Name    string
+		At      time.Time
+		Score   float64
+		Tags    []string
+		Attrs   map[string]interface{}
+		Items   []Content
+		Next    *Event
+		private int
+	}
+
+	var (
+		now   = time.Now()
+		tenth = 0.1
+	)
+	base := func() *Event {
+		return &Event{
+			Name:  "e",
+			At:    now,
+			Score: 0.3,
+			Tags:  []string{"a"},
+			Attrs: map[string]interface{}{"k": 1, "nested": map[string]interface{}{"x": []int{1, 2}}},
+			Items: []Content{{Key: "c0"}, {Key: "c1", Version: 1}},
+		}
+	}
+
+	tests := []struct {
+		mutate func(e *Event)
+		opts   []Option
+		equal  bool
+		path   string
+	}{
+		{mutate: func(e *Event) {}, equal: true},
+		{mutate: func(e *Event) { e.Name = "f" }, path: "Name"},
+		{mutate: func(e *Event) { e.Items[1].Version = 2 }, path: "Items[1].Version"},
+		{mutate: func(e *Event) { e.Items = e.Items[:1] }, path: "Items[1]"},
+		{mutate: func(e *Event) { e.Tags = append(e.Tags, "b") }, path: "Tags[1]"},
+		{mutate: func(e *Event) { e.Attrs["k"] = 2 }, path: "Attrs.k"},
+		{mutate: func(e *Event) { e.Attrs["k"] = int64(1) }, path: "Attrs.k"},
+		{mutate: func(e *Event) { e.Attrs["a.b"] = 1 }, path: `Attrs["a.b"]`},
+		{mutate: func(e *Event) { e.Attrs["nested"].(map[string]interface{})["x"] = []int{1, 3} }, path: "Attrs.nested.x[1]"},
+		{mutate: func(e *Event) { e.Next = &Event{} }, path: "Next"},
+		{mutate: func(e *Event) { e.private = 1 }, path: "private"},
+
+		// Ignored paths.
+		{mutate: func(e *Event) { e.At = e.At.Add(time.Second) }, path: "At"},
+		{mutate: func(e *Event) { e.At = e.At.UTC() }, equal: true},
+		{mutate: func(e *Event) { e.At = e.At.Add(time.Second) }, opts: []Option{WithIgnore("At")}, equal: true},
+		{mutate: func(e *Event) { e.Items[1].Version = 2 }, opts: []Option{WithIgnore("Items.Version")}, equal: true},
+		{mutate: func(e *Event) { e.Items[1].Version = 2 }, opts: []Option{WithIgnore("Items.*.Version")}, equal: true},
+		{mutate: func(e *Event) { e.Items[1].Version = 2 }, opts: []Option{WithIgnore("Items.0.Version")}, path: "Items[1].Version"},
+		{mutate: func(e *Event) { e.Attrs["k"] = 2; e.Name = "f" }, opts: []Option{WithIgnore("**.k", "Name")}, equal: true},
+
+		// Float tolerance.
+		{mutate: func(e *Event) { e.Score = tenth + 0.2 }, path: "Score"},
+		{mutate: func(e *Event) { e.Score = tenth + 0.2 }, opts: []Option{WithFloatTolerance(1e-9)}, equal: true},
+		{mutate: func(e *Event) { e.Score = 0.31 }, opts: []Option{WithFloatTolerance(1e-9)}, path: "Score"},
+		{mutate: func(e *Event) { e.Score = math.NaN() }, opts: []Option{WithFloatTolerance(1)}, path: "Score"},
+
+		// Nil and empty.
+		{mutate: func(e *Event) { e.Tags = nil }, path: "Tags"},
+		{mutate: func(e *Event) { e.Tags = []string{}; e.Attrs = map[string]interface{}{} }, path: "Tags[0]"},
+		{
+			mutate: func(e *Event) { e.Tags = nil },
+			opts:   []Option{WithNilEqualsEmpty()},
+			path:   "Tags",
+		},
+	}
+
+	for i, test := range tests {
+		a, b := base(), base()
+		test.mutate(b)
+		equal, path := Equal(a, b, test.opts...)
+		if equal != test.equal || path != test.path {
+			t.Errorf("[i=%v] Expected equal=%v path=%q but actual equal=%v path=%q", i, test.equal, test.path, equal, path)
+		}
+	}
+
+	// Nil and empty with the option.
+	a, b := base(), base()
+	a.Tags, b.Tags = nil, []string{}
+	a.Attrs, b.Attrs = map[string]interface{}{}, nil
+	if equal, path := Equal(a, b); equal || path != "Tags" {
+		t.Errorf("Expected nil and empty to differ at Tags but got equal=%v path=%q", equal, path)
+	}
+	if equal, path := Equal(a, b, WithNilEqualsEmpty()); !equal {
+		t.Errorf("Expected nil and empty to be equal but differed at path=%q", path)
+	}
+
+	// Mismatched types differ at the root.
+	if equal, path := Equal(1, "1"); equal || path != "" {
+		t.Errorf("Expected root difference but got equal=%v path=%q", equal, path)
+	}
+	if equal, _ := Equal(nil, nil); !equal {
+		t.Errorf("Expected nil to equal nil")
+	}
+
+	// Distinct keys sharing a name are compared individually.
+	if equal, path := Equal(map[interface{}]int{1: 1, "1": 2}, map[interface{}]int{1: 1, "1": 3}); equal || path != "1" {
+		t.Errorf("Expected difference at 1 but got equal=%v path=%q", equal, path)
+	}
+	if equal, path := Equal(map[interface{}]int{1: 1, "1": 2}, map[interface{}]int{1: 1, "1": 2}); !equal {
+		t.Errorf("Expected equal maps but differed at path=%q", path)
+	}
+}
+
+func TestEqualCycle(t *testing.T) {
+	type Node struct {
+		Name string
+		Next *Node
+	}
+
+	var (
+		a = &Node{Name: "a"}
+		b = &Node{Name: "a"}
+	)
+	a.Next = a
+	b.Next = &Node{Name: "a"}
+	b.Next.Next = b
+
+	if equal, path := Equal(a, b); !equal {
+		t.Errorf("Expected cyclic lists to be equal but differed at path=%q", path)
+	}
+
+	b.Next.Name = "b"
+	if equal, path := Equal(a, b); equal || path != "Next.Name" {
+		t.Errorf("Expected difference at Next.Name but got equal=%v path=%q", equal, path)
+	}
+
+	// Tag names are used in the reported path.
+	type Tagged struct {
+		Value int `json:"value"`
+	}
+	if _, path := New(WithTagName("json")).Equal(Tagged{1}, Tagged{2}); path != "value" {
+		t.Errorf("Expected path=%q but actual=%q", "value", path)
+	}
+}
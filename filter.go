@@ -0,0 +1,121 @@
+package metaflector
+
+import (
+	"strings"
+)
+
+// pattern is a compiled path glob.
+//
+// Each component is matched against a single path component, where "*"
+// matches any run of characters and "?" matches exactly one.  A component
+// consisting solely of "**" matches zero or more whole path components.
+type pattern []string
+
+// compilePatterns splits the raw glob strings into include and exclude sets.
+// Patterns prefixed with "!" are excludes, everything else is an include.
+func compilePatterns(raw []string, sep string) (includes []pattern, excludes []pattern) {
+	for _, s := range raw {
+		exclude := strings.HasPrefix(s, "!")
+		if exclude {
+			s = s[1:]
+		}
+		components, err := splitPath(s, sep)
+		if err != nil {
+			components = strings.Split(s, sep)
+		}
+		if exclude {
+			excludes = append(excludes, pattern(components))
+		} else {
+			includes = append(includes, pattern(components))
+		}
+	}
+	return
+}
+
+// Match returns true if the path components fully satisfy the pattern.
+func (p pattern) Match(components []string) bool {
+	return globMatch(p, components, false)
+}
+
+// MatchPrefix returns true if the path components, or some path nested beneath
+// them, could satisfy the pattern.
+func (p pattern) MatchPrefix(components []string) bool {
+	return globMatch(p, components, true)
+}
+
+func globMatch(p pattern, components []string, prefix bool) bool {
+	for len(p) > 0 {
+		if p[0] == "**" {
+			for i := 0; i <= len(components); i++ {
+				if globMatch(p[1:], components[i:], prefix) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(components) == 0 {
+			return prefix
+		}
+		if !wildcardMatch(p[0], components[0]) {
+			return false
+		}
+		p = p[1:]
+		components = components[1:]
+	}
+	return len(components) == 0
+}
+
+// wildcardMatch matches a single path component against a pattern component
+// containing "*" and "?" wildcards.
+func wildcardMatch(p string, s string) bool {
+	var (
+		pi, si = 0, 0
+		starP  = -1
+		starS  = 0
+	)
+	for si < len(s) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == s[si]):
+			pi++
+			si++
+		case pi < len(p) && p[pi] == '*':
+			starP = pi
+			starS = si
+			pi++
+		case starP != -1:
+			pi = starP + 1
+			starS++
+			si = starS
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// allowed returns true if the path components survive the include and exclude
+// patterns.  Terminal paths must fully match an include, whereas non-terminal
+// paths need only be a prefix of one so that traversal may continue beneath
+// them.  Excludes apply to everything beneath a matching path, including the
+// fields of slices of structs, which are flattened into their parent's path.
+func allowed(components []string, terminal bool, includes []pattern, excludes []pattern) bool {
+	for _, p := range excludes {
+		for i := 1; i <= len(components); i++ {
+			if p.Match(components[:i]) {
+				return false
+			}
+		}
+	}
+	if len(includes) == 0 {
+		return true
+	}
+	for _, p := range includes {
+		if terminal && p.Match(components) || !terminal && p.MatchPrefix(components) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package metaflector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+		prefix  bool
+	}{
+		{pattern: "Spec", path: "Spec", match: true, prefix: true},
+		{pattern: "Spec", path: "Spec.Name", match: false, prefix: false},
+		{pattern: "Spec.*", path: "Spec", match: false, prefix: true},
+		{pattern: "Spec.*", path: "Spec.Name", match: true, prefix: true},
+		{pattern: "Spec.*", path: "Spec.Template.Name", match: false, prefix: false},
+		{pattern: "Spec.**", path: "Spec", match: true, prefix: true},
+		{pattern: "Spec.**", path: "Spec.Template.Name", match: true, prefix: true},
+		{pattern: "Spec.**", path: "Status.Name", match: false, prefix: false},
+		{pattern: "**.Name", path: "Name", match: true, prefix: true},
+		{pattern: "**.Name", path: "Spec.Template.Name", match: true, prefix: true},
+		{pattern: "**.Name", path: "Spec.Template", match: false, prefix: true},
+		{pattern: "Spec.**.Name", path: "Spec.Name", match: true, prefix: true},
+		{pattern: "Spec.**.Name", path: "Status.Name", match: false, prefix: false},
+		{pattern: "Con*.K?y", path: "Contents.Key", match: true, prefix: true},
+		{pattern: "Con*.K?y", path: "Contents.Kay", match: true, prefix: true},
+		{pattern: "Con*.K?y", path: "Contents.Keys", match: false, prefix: false},
+		{pattern: "*", path: "", match: true, prefix: true},
+		{pattern: "a*b*c", path: "aXbYbZc", match: true, prefix: true},
+		{pattern: "a*b*c", path: "aXbYbZ", match: false, prefix: false},
+	}
+
+	for i, test := range tests {
+		var (
+			p          = pattern(strings.Split(test.pattern, "."))
+			components = strings.Split(test.path, ".")
+		)
+		if expected, actual := test.match, p.Match(components); actual != expected {
+			t.Errorf("[i=%v] Expected match=%v but actual=%v for pattern=%q path=%q", i, expected, actual, test.pattern, test.path)
+		}
+		if expected, actual := test.prefix, p.MatchPrefix(components); actual != expected {
+			t.Errorf("[i=%v] Expected prefix=%v but actual=%v for pattern=%q path=%q", i, expected, actual, test.pattern, test.path)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package metaflector
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrFlagRedefined is returned by RegisterFlags when a flag of the same name
+// is already defined.
+var ErrFlagRedefined = errors.New("flag redefined")
+
+const (
+	// usageTag is the struct tag holding a field's flag usage string.
+	usageTag = "usage"
+
+	// defaultTag is the struct tag holding a field's default value, parsed
+	// the same way as values given on the command-line.
+	defaultTag = "default"
+)
+
+// RegisterFlags defines a flag on fs for each terminal field of obj, which
+// must be a non-nil pointer to a struct.  Flags are named after the
+// lower-cased path of their field (e.g. "bar.baz.name"; see WithFlagName),
+// and set the field directly when parsed.
+//
+// Supported field types are the same as for LoadEnv.  Usage strings are read
+// from the "usage" struct tag, and a "default" tag is parsed and assigned to
+// the field at registration, e.g.
+//
+//	Timeout time.Duration `usage:"request timeout" default:"30s"`
+//
+// Fields without a default tag keep their current value as the default.
+// Slice flags replace the default the first time they're given and append
+// thereafter, so "--hosts=a,b" and "--hosts=a --hosts=b" are equivalent.
+//
+// Nothing is registered if any of the flags would collide with one already
+// defined on fs, or with each other; ErrFlagRedefined is returned instead.
+// Likewise, a default which fails to parse is reported before any flag is
+// registered or field assigned.
+//
+// Use flag.CommandLine to register onto the default FlagSet.
+func RegisterFlags(fs *flag.FlagSet, obj interface{}, opts ...Option) error {
+	return defaultReflector.RegisterFlags(fs, obj, opts...)
+}
+
+// RegisterFlags is the Reflector equivalent of the package-level
+// RegisterFlags.
+func (r *Reflector) RegisterFlags(fs *flag.FlagSet, obj interface{}, opts ...Option) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrNotSettable
+	}
+
+	var (
+		o        = r.options(opts)
+		settings = r.settings(v.Type(), o)
+		names    = make(map[string]struct{}, len(settings))
+	)
+
+	// Check every name and default before touching fs or obj, so that a
+	// failed registration may be retried.
+	for _, s := range settings {
+		name := flagName(s.components, o)
+		if _, ok := names[name]; ok || fs.Lookup(name) != nil {
+			return &PathError{Path: s.path, Err: ErrFlagRedefined}
+		}
+		names[name] = struct{}{}
+		if def, ok := lookupTag(s.field.Tag, defaultTag); ok {
+			if _, err := parseValue(s.field.Type, def, o.delim()); err != nil {
+				return &PathError{Path: s.path, Err: err}
+			}
+		}
+	}
+
+	values := make([]*flagValue, len(settings))
+	for i, s := range settings {
+		values[i] = &flagValue{
+			r:    r,
+			o:    o,
+			root: v.Elem(),
+			s:    s,
+		}
+		if def, ok := lookupTag(s.field.Tag, defaultTag); ok {
+			if err := values[i].Set(def); err != nil {
+				return err
+			}
+			values[i].set = false
+		}
+	}
+	for i, s := range settings {
+		fs.Var(values[i], flagName(s.components, o), s.field.Tag.Get(usageTag))
+	}
+
+	return nil
+}
+
+// lookupTag returns the value of the key in the tag, and whether it's present,
+// in the same way as reflect.StructTag.Lookup, which needs Go 1.7.
+func lookupTag(tag reflect.StructTag, key string) (string, bool) {
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon.  A space, a quote or a control character is a syntax
+		// error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// Scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if key == name {
+			value, err := strconv.Unquote(qvalue)
+			if err != nil {
+				break
+			}
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// flagName applies the configured transform to the components.
+func flagName(components []string, o *options) string {
+	if o.flagName != nil {
+		return o.flagName(components)
+	}
+	return strings.ToLower(strings.Join(components, o.sep()))
+}
+
+// flagValue is a flag.Value which reads and writes a struct field.
+type flagValue struct {
+	r    *Reflector
+	o    *options
+	root reflect.Value
+	s    setting
+	set  bool // True once the flag has been given.
+}
+
+// field returns the current value of the field, or the zero Value if it lies
+// beneath a nil pointer.
+func (f *flagValue) field() reflect.Value {
+	v := f.root
+	for _, c := range f.s.components {
+		if v = f.r.fieldByName(indirect(v), c, f.o.tagName); !v.IsValid() {
+			break
+		}
+	}
+	return v
+}
+
+func (f *flagValue) String() string {
+	// The flag package calls String on zero values to detect default values.
+	if f == nil || f.r == nil {
+		return ""
+	}
+	v := indirect(f.field())
+	if !v.IsValid() || v.Kind() == reflect.Slice && v.Len() == 0 {
+		return ""
+	}
+	if isList(v.Type()) {
+		elems := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if elem := indirect(v.Index(i)); elem.IsValid() {
+				elems = append(elems, formatValue(elem))
+			}
+		}
+		return strings.Join(elems, f.o.delim())
+	}
+	return formatValue(v)
+}
+
+func (f *flagValue) Set(s string) error {
+	value, err := parseValue(f.s.field.Type, s, f.o.delim())
+	if err != nil {
+		return &PathError{Path: f.s.path, Err: err}
+	}
+	if f.set && value.Kind() == reflect.Slice && f.s.field.Type.Kind() == reflect.Slice {
+		if cur := f.field(); cur.IsValid() {
+			value = reflect.AppendSlice(cur, value)
+		}
+	}
+	if err := f.r.set(f.root, f.s.components, value.Interface(), f.o); err != nil {
+		return &PathError{Path: f.s.path, Err: err}
+	}
+	f.set = true
+	return nil
+}
+
+// Get implements flag.Getter.
+func (f *flagValue) Get() interface{} {
+	if v := f.field(); v.IsValid() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be given without a value, e.g. "--debug".
+func (f *flagValue) IsBoolFlag() bool {
+	t := f.s.field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
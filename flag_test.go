@@ -0,0 +1,200 @@
+package metaflector
+
+import (
+	"bytes"
+	"flag"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	FlagConfig struct {
+		Name    string        `usage:"service name" default:"svc"`
+		Debug   bool          `usage:"enable debug logging"`
+		Timeout time.Duration `usage:"request timeout" default:"30s"`
+		Hosts   []string      `usage:"upstream hosts" default:"a,b"`
+		Addr    net.IP        `usage:"listen address"`
+		Limit   *int          `usage:"optional limit"`
+		Bar     FlagBar
+		Baz     *FlagBaz
+	}
+
+	FlagBar struct {
+		Ratio float64 `usage:"sampling ratio" default:"0.25"`
+	}
+
+	FlagBaz struct {
+		Name string `usage:"baz name"`
+	}
+)
+
+func TestRegisterFlags(t *testing.T) {
+	var (
+		cfg = &FlagConfig{Debug: true}
+		fs  = flag.NewFlagSet("test", flag.ContinueOnError)
+	)
+
+	if err := RegisterFlags(fs, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Defaults are applied at registration.
+	expected := &FlagConfig{
+		Name:    "svc",
+		Debug:   true,
+		Timeout: 30 * time.Second,
+		Hosts:   []string{"a", "b"},
+		Bar:     FlagBar{Ratio: 0.25},
+	}
+	if actual := cfg; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected defaults=%+v but actual=%+v", expected, actual)
+	}
+
+	names := []string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name+"="+f.DefValue+" ("+f.Usage+")")
+	})
+	expectedNames := []string{
+		"addr= (listen address)",
+		"bar.ratio=0.25 (sampling ratio)",
+		"baz.name= (baz name)",
+		"debug=true (enable debug logging)",
+		"hosts=a,b (upstream hosts)",
+		"limit= (optional limit)",
+		"name=svc (service name)",
+		"timeout=30s (request timeout)",
+	}
+	if actual := names; !reflect.DeepEqual(actual, expectedNames) {
+		t.Errorf("Expected flags=%# v but actual=%# v", expectedNames, actual)
+	}
+
+	args := []string{
+		"--name=api",
+		"--debug=false",
+		"--timeout", "1m",
+		"--hosts", "x",
+		"--hosts", "y,z",
+		"--addr", "127.0.0.1",
+		"--limit", "5",
+		"--bar.ratio", "1",
+		"--baz.name", "nested",
+	}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	limit := 5
+	expected = &FlagConfig{
+		Name:    "api",
+		Timeout: time.Minute,
+		Hosts:   []string{"x", "y", "z"},
+		Addr:    net.ParseIP("127.0.0.1"),
+		Limit:   &limit,
+		Bar:     FlagBar{Ratio: 1},
+		Baz:     &FlagBaz{Name: "nested"},
+	}
+	if actual := cfg; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected parsed=%+v but actual=%+v", expected, actual)
+	}
+	if expected, actual := "127.0.0.1", fs.Lookup("addr").Value.String(); actual != expected {
+		t.Errorf("Expected addr=%q but actual=%q", expected, actual)
+	}
+	if expected, actual := interface{}(time.Minute), fs.Lookup("timeout").Value.(flag.Getter).Get(); actual != expected {
+		t.Errorf("Expected timeout=%v but actual=%v", expected, actual)
+	}
+
+	// Boolean flags may be given without a value.
+	if err := fs.Parse([]string{"--debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Debug {
+		t.Errorf("Expected debug to be enabled")
+	}
+
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	if err := fs.Parse([]string{"--timeout", "soon"}); err == nil {
+		t.Errorf("Expected error for invalid duration")
+	}
+	if !strings.Contains(out.String(), "request timeout (default 30s)") {
+		t.Errorf("Expected usage to mention default, got:\n%s", out.String())
+	}
+}
+
+func TestRegisterFlagsOptions(t *testing.T) {
+	type Bad struct {
+		Host string `default:"localhost"`
+		Port int    `default:"eighty"`
+	}
+
+	var (
+		cfg = &FlagConfig{}
+		fs  = flag.NewFlagSet("test", flag.ContinueOnError)
+	)
+	err := New(WithSeparator("-")).RegisterFlags(fs, cfg, WithFilter("Bar-*", "Baz-*"), WithFlagName(func(components []string) string {
+		return "cfg-" + strings.ToLower(strings.Join(components, "-"))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	if expected, actual := []string{"cfg-bar-ratio", "cfg-baz-name"}, names; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected flags=%v but actual=%v", expected, actual)
+	}
+
+	// An invalid default leaves both the FlagSet and the object untouched.
+	var (
+		bad   = &Bad{}
+		badFs = flag.NewFlagSet("bad", flag.ContinueOnError)
+	)
+	if err := RegisterFlags(badFs, bad); err == nil || err.(*PathError).Path != "Port" {
+		t.Errorf("Expected path error for invalid default but got %v", err)
+	}
+	if badFs.Lookup("host") != nil || bad.Host != "" {
+		t.Errorf("Expected nothing to be registered or assigned but got flag=%v host=%q", badFs.Lookup("host"), bad.Host)
+	}
+	if expected, actual := ErrNotSettable, RegisterFlags(fs, FlagConfig{}); actual != expected {
+		t.Errorf("Expected err=%v but actual=%v", expected, actual)
+	}
+
+	// Collisions are reported rather than panicking, and register nothing.
+	fs = flag.NewFlagSet("collide", flag.ContinueOnError)
+	fs.String("baz.name", "", "")
+	if err := RegisterFlags(fs, &FlagConfig{}); err == nil || err.(*PathError).Err != ErrFlagRedefined {
+		t.Errorf("Expected err=%v but actual=%v", ErrFlagRedefined, err)
+	}
+	if fs.Lookup("bar.ratio") != nil {
+		t.Errorf("Expected no flags to be registered after a collision")
+	}
+	err = RegisterFlags(flag.NewFlagSet("self", flag.ContinueOnError), &FlagConfig{}, WithFlagName(func([]string) string { return "same" }))
+	if err == nil || err.(*PathError).Err != ErrFlagRedefined {
+		t.Errorf("Expected err=%v but actual=%v", ErrFlagRedefined, err)
+	}
+}
+
+func TestLookupTag(t *testing.T) {
+	tests := []struct {
+		tag      reflect.StructTag
+		value    string
+		expected bool
+	}{
+		{tag: `default:"30s"`, value: "30s", expected: true},
+		{tag: `usage:"a \"quoted\" word" default:""`, value: "", expected: true},
+		{tag: `json:"name,omitempty"  default:"x:y"`, value: "x:y", expected: true},
+		{tag: `usage:"no default"`},
+		{tag: `default`},
+		{tag: ``},
+	}
+
+	for i, test := range tests {
+		if value, ok := lookupTag(test.tag, defaultTag); value != test.value || ok != test.expected {
+			t.Errorf("[i=%v] Expected value=%q ok=%v but actual value=%q ok=%v for tag=%q", i, test.value, test.expected, value, ok, test.tag)
+		}
+	}
+}
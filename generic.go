@@ -0,0 +1,175 @@
+package metaflector
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Generic trees are the untyped values produced by decoding JSON or YAML into
+// an interface{}: objects are map[string]interface{} (or the
+// map[interface{}]interface{} of YAML decoders), lists are []interface{}, and
+// everything else is a scalar.  Objects take the place of structs, with their
+// keys as field names, so paths, filters and fan-outs work on them just as
+// they do on typed values.
+
+// isObject returns true if v is (or points to) a generic object.
+func isObject(v reflect.Value) bool {
+	v = indirect(v)
+	if v.Kind() != reflect.Map || v.Type().Elem().Kind() != reflect.Interface {
+		return false
+	}
+	switch v.Type().Key().Kind() {
+	case reflect.String, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// isGenericList returns true if v is (or points to) a generic list, i.e. a
+// slice or array of interface{} values which isn't a heterogeneous collection
+// of structs.
+func isGenericList(v reflect.Value) bool {
+	v = indirect(v)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array || v.Type().Elem().Kind() != reflect.Interface {
+		return false
+	}
+	for i := 0; i < v.Len(); i++ {
+		if elem := indirect(v.Index(i)); elem.IsValid() {
+			return elem.Kind() != reflect.Struct
+		}
+	}
+	return true
+}
+
+// hasObjects returns true if generic list v contains any objects, including
+// within nested lists.
+func hasObjects(v reflect.Value) bool {
+	v = indirect(v)
+	for i := 0; i < v.Len(); i++ {
+		elem := indirect(v.Index(i))
+		if isObject(elem) || isGenericList(elem) && hasObjects(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// genericFields returns the field names and values of generic object or list
+// v, sorted by name.  The fields of a list are the union of those of the
+// objects within it, each valued with a []interface{} of the values found
+// across them, as Get would return.
+func genericFields(v reflect.Value) ([]string, []reflect.Value) {
+	v = indirect(v)
+
+	if isObject(v) {
+		keys := sortedKeys(v)
+		names := make([]string, len(keys))
+		values := make([]reflect.Value, len(keys))
+		for i, k := range keys {
+			names[i] = keyName(k)
+			values[i] = v.MapIndex(k)
+		}
+		return names, values
+	}
+
+	collected := map[string][]interface{}{}
+	var collect func(list reflect.Value)
+	collect = func(list reflect.Value) {
+		for i := 0; i < list.Len(); i++ {
+			elem := indirect(list.Index(i))
+			switch {
+			case isObject(elem):
+				for _, k := range elem.MapKeys() {
+					name := keyName(k)
+					collected[name] = append(collected[name], elem.MapIndex(k).Interface())
+				}
+			case isGenericList(elem):
+				collect(elem)
+			}
+		}
+	}
+	collect(v)
+
+	names := make([]string, 0, len(collected))
+	for name := range collected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]reflect.Value, len(names))
+	for i, name := range names {
+		values[i] = reflect.ValueOf(collected[name])
+	}
+	return names, values
+}
+
+// eachGenericField invokes fn for each field of generic object or list v,
+// naming them beneath prefix.
+func (r *Reflector) eachGenericField(v reflect.Value, prefix string, o *options, fn IterFunc) {
+	sep := o.sep()
+	names, values := genericFields(v)
+	for i, name := range names {
+		name = quoteComponent(name, sep)
+		if prefix != "" {
+			name = appendPath(prefix, name, sep)
+		}
+		r.genericField(values[i], name, o, fn)
+	}
+}
+
+// genericField invokes fn for the generic value v named name.  As with slices
+// of structs, lists containing objects are descended into immediately, while
+// other lists are terminal.
+func (r *Reflector) genericField(v reflect.Value, name string, o *options, fn IterFunc) {
+	x := indirect(v)
+	switch {
+	case !x.IsValid():
+		fn(nil, name, reflect.Interface)
+	case isCustom(v.Interface()):
+		// Descended into by way of its FieldLister.
+		fn(v.Interface(), name, reflect.ValueOf(v.Interface()).Kind())
+	case isObject(x):
+		fn(x.Interface(), name, reflect.Map)
+	case isGenericList(x) && hasObjects(x):
+		r.eachGenericField(x, name, o, fn)
+	default:
+		fn(unreflect(x), name, x.Kind())
+	}
+}
+
+// sortedKeys returns the keys of map v, sorted by name.
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Sort(byKeyName(keys))
+	return keys
+}
+
+// mapIndex looks up the key of map v named by a path component.  Only maps
+// with string keys, or interface keys such as those decoded from YAML, are
+// addressable.  The key is invalid if the map can't be addressed by name, and
+// the value is invalid if there's no such key.
+func mapIndex(v reflect.Value, name string) (key reflect.Value, value reflect.Value) {
+	keyType := v.Type().Key()
+	switch keyType.Kind() {
+	case reflect.String:
+		key = reflect.ValueOf(name).Convert(keyType)
+		return key, v.MapIndex(key)
+
+	case reflect.Interface:
+		key = reflect.ValueOf(name)
+		if !key.Type().Implements(keyType) {
+			return reflect.Value{}, reflect.Value{}
+		}
+		if value = v.MapIndex(key); value.IsValid() {
+			return key, value
+		}
+		// Non-string keys (e.g. YAML integers) are named as they print.
+		for _, k := range v.MapKeys() {
+			if keyName(k) == name {
+				return k, v.MapIndex(k)
+			}
+		}
+		return key, reflect.Value{}
+	}
+
+	return reflect.Value{}, reflect.Value{}
+}
@@ -0,0 +1,178 @@
+package metaflector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const genericJSON = `{
+	"kind": "List",
+	"items": [
+		{"metadata": {"name": "web", "labels": {"app": "web"}}, "spec": {"replicas": 3, "ports": [80, 443]}},
+		{"metadata": {"name": "db", "annotations": {"example.com/owner": "ops"}}, "spec": {"replicas": 1, "paused": null}},
+		"stray"
+	],
+	"empty": {},
+	"tags": []
+}`
+
+func decodeGeneric(t *testing.T) interface{} {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(genericJSON), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestGenericTerminalFields(t *testing.T) {
+	doc := decodeGeneric(t)
+
+	// As decoded from YAML.
+	yamlDoc := map[interface{}]interface{}{
+		"name": "x",
+		1:      map[interface{}]interface{}{"on": true},
+		"list": []interface{}{map[interface{}]interface{}{"a": 1}, map[interface{}]interface{}{"b": 2}},
+	}
+
+	type Holder struct {
+		Name  string
+		Tags  []string
+		Attrs map[string]interface{}
+		Items []interface{}
+	}
+
+	tests := []struct {
+		obj      interface{}
+		opts     []Option
+		expected []string
+	}{
+		{
+			obj: doc,
+			expected: []string{
+				`items.metadata.annotations["example.com/owner"]`,
+				"items.metadata.labels.app",
+				"items.metadata.name",
+				"items.spec.paused",
+				"items.spec.ports",
+				"items.spec.replicas",
+				"kind",
+				"tags",
+			},
+		},
+		{
+			obj:      &doc,
+			opts:     []Option{WithFilter("items/spec/*"), WithSeparator("/")},
+			expected: []string{"items/spec/paused", "items/spec/ports", "items/spec/replicas"},
+		},
+		{
+			obj:      doc,
+			opts:     []Option{WithMaxDepth(2)},
+			expected: []string{"kind", "tags"},
+		},
+		{
+			obj:      doc.(map[string]interface{})["items"],
+			opts:     []Option{WithFilter("**.name")},
+			expected: []string{"metadata.name"},
+		},
+		{
+			obj:      yamlDoc,
+			expected: []string{"1.on", "list.a", "list.b", "name"},
+		},
+		{
+			obj: Holder{
+				Tags:  []string{"a", "b"},
+				Attrs: map[string]interface{}{"color": "red", "size": map[string]interface{}{"w": 1, "h": 2}},
+				Items: []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2, "extra": true}},
+			},
+			expected: []string{"Attrs.color", "Attrs.size.h", "Attrs.size.w", "Items.extra", "Items.id", "Name", "Tags"},
+		},
+		{
+			obj:      []interface{}{1, "two", nil},
+			expected: []string{},
+		},
+	}
+
+	for i, test := range tests {
+		if actual := TerminalFields(test.obj, test.opts...); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("[i=%v] Expected fields=%# v but actual=%# v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestGenericEachField(t *testing.T) {
+	doc := decodeGeneric(t)
+
+	type field struct {
+		value interface{}
+		kind  reflect.Kind
+	}
+	actual := map[string]field{}
+	ok := EachField(doc, func(obj interface{}, name string, kind reflect.Kind) {
+		actual[name] = field{value: obj, kind: kind}
+	})
+	if !ok {
+		t.Fatalf("Expected EachField to accept a generic object")
+	}
+
+	expected := map[string]field{
+		"empty": {value: map[string]interface{}{}, kind: reflect.Map},
+		`items.metadata.annotations["example.com/owner"]`: {value: []interface{}{"ops"}, kind: reflect.Slice},
+		"items.metadata.labels.app":                       {value: []interface{}{"web"}, kind: reflect.Slice},
+		"items.metadata.name":                             {value: []interface{}{"web", "db"}, kind: reflect.Slice},
+		"items.spec.paused":                               {value: []interface{}{nil}, kind: reflect.Slice},
+		"items.spec.ports":                                {value: []interface{}{[]interface{}{80.0, 443.0}}, kind: reflect.Slice},
+		"items.spec.replicas":                             {value: []interface{}{3.0, 1.0}, kind: reflect.Slice},
+		"kind":                                            {value: "List", kind: reflect.String},
+		"tags":                                            {value: []interface{}{}, kind: reflect.Slice},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+
+	if ok := EachField(map[string]string{"a": "b"}, func(interface{}, string, reflect.Kind) {}); ok {
+		t.Errorf("Expected EachField to reject a typed map")
+	}
+	if obj, ok := ResolveUnderlying(&doc); !ok || !reflect.DeepEqual(obj, doc) {
+		t.Errorf("Expected ResolveUnderlying to resolve a generic object but actual=%v ok=%v", obj, ok)
+	}
+}
+
+func TestGenericGetAndSet(t *testing.T) {
+	doc := decodeGeneric(t)
+
+	if expected, actual := []interface{}{"web", "db", nil}, Get(doc, "items.metadata.name"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	if expected, actual := "ops", Get(doc, `items[1].metadata.annotations["example.com/owner"]`); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+
+	yamlDoc := map[interface{}]interface{}{1: map[interface{}]interface{}{"on": true}}
+	if expected, actual := true, Get(yamlDoc, "1.on"); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	if err := Set(&yamlDoc, "1.off", false); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if expected, actual := false, yamlDoc[1].(map[interface{}]interface{})["off"]; actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+
+	if err := Set(&doc, "items[0].spec.strategy.type", "Recreate"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if expected, actual := "Recreate", Get(doc, "items[0].spec.strategy.type"); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	if err := Set(&doc, "items[0].spec.ports.extra", 1); err == nil {
+		t.Errorf("Expected an error setting a field of a list of numbers")
+	}
+
+	var empty interface{}
+	if err := Set(&empty, "a.b", 1); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	} else if expected := map[string]interface{}{"a": map[string]interface{}{"b": 1}}; !reflect.DeepEqual(empty, expected) {
+		t.Errorf("Expected value=%v but actual=%v", expected, empty)
+	}
+}
@@ -0,0 +1,146 @@
+package metaflector
+
+import (
+	"reflect"
+)
+
+// GetWithPaths resolves the path in the same manner as Get, but instead of
+// nesting the values found by fanning out over slices and arrays, returns each
+// resolved value along with its concrete path, e.g. "Bar.Baz.Contents[2].Key".
+//
+// Nil elements encountered while fanning out are reported in place with
+// Skipped set, e.g. {Path: "Bar.Baz.ContentPtrs[1]", Skipped: true}.
+//
+// The path may also contain "**", which matches zero or more intermediate
+// fields, map values or elements.  e.g. "Bar.**.Key" finds every Key anywhere
+// beneath Bar.  Matches are returned shallowest first.
+func GetWithPaths(obj interface{}, path string) []Match {
+	return defaultReflector.GetWithPaths(obj, path)
+}
+
+// GetWithPaths is the Reflector equivalent of the package-level GetWithPaths.
+func (r *Reflector) GetWithPaths(obj interface{}, path string) []Match {
+	o := r.options(nil)
+	components, err := pathComponents(path, o.sep())
+	if err != nil {
+		return nil
+	}
+	w := &walker{r: r, o: o}
+	return w.resolve(node{v: reflect.ValueOf(obj)}, components, []Match{})
+}
+
+// hasRecursiveWildcard returns true if any of the components is an unquoted
+// "**".
+func hasRecursiveWildcard(components []string) bool {
+	for _, c := range components {
+		if c == anyDescendant {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve appends a Match for every value reached by following the components
+// from n.
+func (w *walker) resolve(n node, components []string, out []Match) []Match {
+	if len(components) == 0 {
+		var value interface{}
+		if n.v.IsValid() {
+			value = unreflect(n.v)
+		}
+		return append(out, Match{Path: n.path, Value: value})
+	}
+
+	name := components[0]
+
+	if name == anyDescendant {
+		return w.descend(n, components[1:], out)
+	}
+
+	switch v := indirect(n.v); v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if i, ok := parseIndex(name); ok {
+			if i < v.Len() {
+				out = w.resolve(w.element(n, v, i), components[1:], out)
+			}
+			return out
+		}
+		rest := components
+		if name == anyElement {
+			rest = components[1:]
+		}
+		// Fan out over each element, flagging those which are nil.
+		for i := 0; i < v.Len(); i++ {
+			if ele := w.element(n, v, i); indirect(ele.v).IsValid() {
+				out = w.resolve(ele, rest, out)
+			} else {
+				out = append(out, Match{Path: ele.path, Skipped: true})
+			}
+		}
+		return out
+	}
+
+	if child, ok := w.child(n, name); ok {
+		out = w.resolve(child, components[1:], out)
+	}
+	return out
+}
+
+// descend resolves the remaining components against n and every node beneath
+// it, using a BFS queue-based traversal as TerminalFields does.  Pointers
+// already present amongst a node's ancestors aren't expanded again, so cyclic
+// structures terminate.
+func (w *walker) descend(n node, rest []string, out []Match) []Match {
+	type item struct {
+		node
+		ancestors []uintptr
+	}
+
+	var (
+		queue = []item{{node: n}}
+		seen  = map[string]struct{}{}
+	)
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		// The same value may be reached through more than one expansion of
+		// the wildcard, so only keep the first of each concrete path.  Nil
+		// elements aren't flagged, as the wildcard visits every element
+		// individually anyway.
+		for _, m := range w.resolve(cur.node, rest, nil) {
+			if _, ok := seen[m.Path]; !ok && !m.Skipped {
+				seen[m.Path] = struct{}{}
+				out = append(out, m)
+			}
+		}
+
+		ancestors := cur.ancestors
+		v := cur.v
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			if containsPointer(ancestors, v.Pointer()) {
+				continue
+			}
+			ancestors = append(ancestors[:len(ancestors):len(ancestors)], v.Pointer())
+		}
+
+		for _, child := range w.children(cur.node) {
+			queue = append(queue, item{node: child, ancestors: ancestors})
+		}
+	}
+
+	return out
+}
+
+func containsPointer(pointers []uintptr, p uintptr) bool {
+	for _, q := range pointers {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
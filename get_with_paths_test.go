@@ -0,0 +1,257 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetWithPathsRecursiveWildcard(t *testing.T) {
+	type Node struct {
+		Key      string
+		Children []*Node
+		Attrs    map[string]interface{}
+	}
+
+	obj := &Foo{
+		Bar: Bar{
+			Baz: Baz{
+				Name: "baz",
+				Contents: []Content{
+					{Key: "c0"},
+					{Key: "c1"},
+				},
+				ContentPtrs: []*Content{
+					nil,
+					{Key: "p1"},
+				},
+			},
+			Stock: "bar",
+		},
+		StructPtr: &Bar{
+			Baz: Baz{
+				Contents: []Content{
+					{Key: "s0"},
+				},
+			},
+		},
+		Contents: []Content{
+			{Key: "top"},
+		},
+	}
+
+	tests := []struct {
+		obj      interface{}
+		path     string
+		expected []Match
+	}{
+		{
+			obj:  obj,
+			path: "Bar.**.Key",
+			expected: []Match{
+				{Path: "Bar.Baz.Contents[0].Key", Value: "c0"},
+				{Path: "Bar.Baz.Contents[1].Key", Value: "c1"},
+				{Path: "Bar.Baz.ContentPtrs[1].Key", Value: "p1"},
+			},
+		},
+		{
+			obj:  obj,
+			path: "**.Key",
+			expected: []Match{
+				{Path: "Contents[0].Key", Value: "top"},
+				{Path: "Bar.Baz.Contents[0].Key", Value: "c0"},
+				{Path: "Bar.Baz.Contents[1].Key", Value: "c1"},
+				{Path: "Bar.Baz.ContentPtrs[1].Key", Value: "p1"},
+				{Path: "StructPtr.Baz.Contents[0].Key", Value: "s0"},
+			},
+		},
+		{
+			obj:  obj,
+			path: "**.Baz.Name",
+			expected: []Match{
+				{Path: "Bar.Baz.Name", Value: "baz"},
+				{Path: "StructPtr.Baz.Name", Value: ""},
+			},
+		},
+		{
+			obj:  obj,
+			path: "Bar.**.Stock",
+			expected: []Match{
+				{Path: "Bar.Stock", Value: "bar"},
+			},
+		},
+		{
+			obj:  obj,
+			path: "Bar.Baz.Contents.**",
+			expected: []Match{
+				{Path: "Bar.Baz.Contents", Value: []Content{{Key: "c0"}, {Key: "c1"}}},
+				{Path: "Bar.Baz.Contents[0]", Value: Content{Key: "c0"}},
+				{Path: "Bar.Baz.Contents[1]", Value: Content{Key: "c1"}},
+				{Path: "Bar.Baz.Contents[0].Key", Value: "c0"},
+				{Path: "Bar.Baz.Contents[0].Value", Value: ""},
+				{Path: "Bar.Baz.Contents[0].Version", Value: int64(0)},
+				{Path: "Bar.Baz.Contents[1].Key", Value: "c1"},
+				{Path: "Bar.Baz.Contents[1].Value", Value: ""},
+				{Path: "Bar.Baz.Contents[1].Version", Value: int64(0)},
+			},
+		},
+		{
+			obj:      obj,
+			path:     "Bar.**.Missing",
+			expected: []Match{},
+		},
+		{
+			obj: &Node{
+				Key: "root",
+				Children: []*Node{
+					{Key: "a", Attrs: map[string]interface{}{"Key": "attr"}},
+					{Key: "b", Children: []*Node{{Key: "b0"}}},
+				},
+			},
+			path: "**.Key",
+			expected: []Match{
+				{Path: "Key", Value: "root"},
+				{Path: "Children[0].Key", Value: "a"},
+				{Path: "Children[1].Key", Value: "b"},
+				{Path: "Children[0].Attrs.Key", Value: "attr"},
+				{Path: "Children[1].Children[0].Key", Value: "b0"},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.expected, GetWithPaths(test.obj, test.path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected matches=%# v but actual=%# v for path=%q", i, expected, actual, test.path)
+		}
+	}
+
+	// Get returns just the values.
+	if expected, actual := []interface{}{"c0", "c1", "p1"}, Get(obj, "Bar.**.Key"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected values=%# v but actual=%# v", expected, actual)
+	}
+
+	// Cyclic structures terminate.
+	cyclic := &Node{Key: "loop"}
+	cyclic.Children = []*Node{cyclic}
+	if expected, actual := []Match{{Path: "Key", Value: "loop"}, {Path: "Children[0].Key", Value: "loop"}}, GetWithPaths(cyclic, "**.Key"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected matches=%# v but actual=%# v", expected, actual)
+	}
+}
+
+func TestGetWithPaths(t *testing.T) {
+	tests := []struct {
+		obj      interface{}
+		path     string
+		expected []Match
+	}{
+		{
+			obj:      Content{Key: "k"},
+			path:     "",
+			expected: []Match{{Path: "", Value: Content{Key: "k"}}},
+		},
+		{
+			obj:      &Content{Key: "k"},
+			path:     "Key",
+			expected: []Match{{Path: "Key", Value: "k"}},
+		},
+		{
+			obj: Foo{
+				Bar: Bar{
+					Baz: Baz{
+						Contents: []Content{
+							{Key: "a"},
+							{Key: "b"},
+							{Key: "c"},
+						},
+					},
+				},
+			},
+			path: "Bar.Baz.Contents.Key",
+			expected: []Match{
+				{Path: "Bar.Baz.Contents[0].Key", Value: "a"},
+				{Path: "Bar.Baz.Contents[1].Key", Value: "b"},
+				{Path: "Bar.Baz.Contents[2].Key", Value: "c"},
+			},
+		},
+		{
+			obj: Foo{
+				Bar: Bar{
+					Baz: Baz{
+						ContentPtrs: []*Content{
+							{Key: "a"},
+							nil,
+							{Key: "c"},
+						},
+					},
+				},
+			},
+			path: "Bar.Baz.ContentPtrs.Key",
+			expected: []Match{
+				{Path: "Bar.Baz.ContentPtrs[0].Key", Value: "a"},
+				{Path: "Bar.Baz.ContentPtrs[1]", Skipped: true},
+				{Path: "Bar.Baz.ContentPtrs[2].Key", Value: "c"},
+			},
+		},
+		{
+			obj: Baz{
+				PtrContentPtrPtrs: &[]**Content{
+					nil,
+					&notHotdogPtr,
+				},
+			},
+			path: "PtrContentPtrPtrs.Value",
+			expected: []Match{
+				{Path: "PtrContentPtrPtrs[0]", Skipped: true},
+				{Path: "PtrContentPtrPtrs[1].Value", Value: "hotdog"},
+			},
+		},
+		{
+			obj: [][]*Content{
+				{nil, {Key: "k01"}},
+				{{Key: "k10"}},
+			},
+			path: "Key",
+			expected: []Match{
+				{Path: "[0][0]", Skipped: true},
+				{Path: "[0][1].Key", Value: "k01"},
+				{Path: "[1][0].Key", Value: "k10"},
+			},
+		},
+		{
+			obj: Foo{
+				Contents: []Content{
+					{Key: "a"},
+					{Key: "b"},
+				},
+			},
+			path:     "Contents[1].Key",
+			expected: []Match{{Path: "Contents[1].Key", Value: "b"}},
+		},
+		{
+			obj:      Foo{},
+			path:     "StructPtr.Stock",
+			expected: []Match{},
+		},
+		{
+			obj:      Foo{},
+			path:     `Contents["unterminated`,
+			expected: nil,
+		},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.expected, GetWithPaths(test.obj, test.path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected matches=%# v but actual=%# v for path=%q", i, expected, actual, test.path)
+		}
+	}
+
+	// Every non-skipped concrete path resolves back to its value.
+	obj := Foo{Bar: Bar{Baz: Baz{ContentPtrs: []*Content{nil, {Key: "x", Version: 2}}}}}
+	for i, m := range GetWithPaths(obj, "Bar.Baz.ContentPtrs.Version") {
+		if m.Skipped {
+			continue
+		}
+		if expected, actual := m.Value, Get(obj, m.Path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected value=%v but actual=%v for path=%q", i, expected, actual, m.Path)
+		}
+	}
+}
@@ -0,0 +1,759 @@
+package metaflector
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidQuery is returned when a JSONPath expression cannot be parsed.
+var ErrInvalidQuery = errors.New("invalid JSONPath expression")
+
+// Match is a value found by a query, along with the concrete path (including
+// any slice or array indexes) at which it was found.
+type Match struct {
+	Path  string
+	Value interface{}
+
+	// Skipped is set by GetWithPaths when the element at Path was nil, so
+	// the remainder of the path could not be resolved beneath it.
+	Skipped bool
+}
+
+// Query evaluates a JSONPath expression directly against Go structs, slices,
+// arrays and maps, returning each matched value along with its concrete path.
+//
+// The supported syntax follows kubectl:
+//
+//	$.items[*].name               child names and wildcards
+//	$.items[0,2]  $.items[-1:]    index unions and slices
+//	$['example.com/owner']        quoted names
+//	$..name                       recursive descent
+//	$.items[?(@.active==true)]    filters, with ==, !=, <, <=, >, >=, &&, ||, !
+//
+// The leading "$" is optional, as are enclosing braces.  Field names are
+// resolved the same way as for Get, so use a Reflector configured with
+// WithTagName("json") to query by json names.
+func Query(obj interface{}, expr string) ([]Match, error) {
+	return defaultReflector.Query(obj, expr)
+}
+
+// Query is the Reflector equivalent of the package-level Query.
+func (r *Reflector) Query(obj interface{}, expr string) ([]Match, error) {
+	p := &jpParser{expr: expr}
+	steps, err := p.parse()
+	if err != nil {
+		return nil, &PathError{Path: expr, Err: err}
+	}
+
+	var (
+		e    = &jpEval{walker: walker{r: r, o: r.options(nil)}}
+		root = node{v: reflect.ValueOf(obj)}
+	)
+	e.root = root
+
+	nodes := e.evalSteps([]node{root}, steps)
+
+	matches := make([]Match, 0, len(nodes))
+	for _, n := range nodes {
+		var value interface{}
+		if n.v.IsValid() {
+			value = unreflect(n.v)
+		}
+		matches = append(matches, Match{Path: n.path, Value: value})
+	}
+	return matches, nil
+}
+
+// JSONPath step kinds.
+const (
+	jpName = iota
+	jpWildcard
+	jpIndexes
+	jpSlice
+	jpFilter
+)
+
+type jpStep struct {
+	kind      int
+	recursive bool
+	names     []string
+	indexes   []int
+	slice     [3]*int // start, end, step
+	filter    jpExpr
+}
+
+// jpExpr is a node of a filter expression.
+type jpExpr interface {
+	test(e *jpEval, current node) bool
+}
+
+type (
+	jpOr struct {
+		l, r jpExpr
+	}
+
+	jpAnd struct {
+		l, r jpExpr
+	}
+
+	jpNot struct {
+		x jpExpr
+	}
+
+	jpExists struct {
+		operand jpOperand
+	}
+
+	jpCompare struct {
+		op   string
+		l, r jpOperand
+	}
+)
+
+// jpOperand is either a literal or a path relative to the current node ("@")
+// or the root ("$").
+type jpOperand struct {
+	literal   interface{}
+	isLiteral bool
+	absolute  bool
+	steps     []jpStep
+}
+
+func (x jpOr) test(e *jpEval, n node) bool  { return x.l.test(e, n) || x.r.test(e, n) }
+func (x jpAnd) test(e *jpEval, n node) bool { return x.l.test(e, n) && x.r.test(e, n) }
+func (x jpNot) test(e *jpEval, n node) bool { return !x.x.test(e, n) }
+
+// test returns true if the operand matched a non-nil value.
+func (x jpExists) test(e *jpEval, n node) bool {
+	start := n
+	if x.operand.absolute {
+		start = e.root
+	}
+	for _, n := range e.evalSteps([]node{start}, x.operand.steps) {
+		if v := indirect(n.v); v.IsValid() && !((v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (x jpCompare) test(e *jpEval, n node) bool {
+	l, ok := x.l.value(e, n)
+	if !ok {
+		return false
+	}
+	r, ok := x.r.value(e, n)
+	if !ok {
+		return false
+	}
+	l, r = normalize(l), normalize(r)
+
+	switch x.op {
+	case "==":
+		return reflect.DeepEqual(l, r)
+	case "!=":
+		return !reflect.DeepEqual(l, r)
+	}
+
+	switch lv := l.(type) {
+	case float64:
+		if rv, ok := r.(float64); ok {
+			return compareOrdered(x.op, lv < rv, lv == rv)
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			return compareOrdered(x.op, lv < rv, lv == rv)
+		}
+	}
+	return false
+}
+
+func compareOrdered(op string, less bool, equal bool) bool {
+	switch op {
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+// normalize converts numeric values (including the json.Number values of a
+// Decoder with UseNumber) to float64 and named string and bool types to their
+// underlying types so that they may be compared.
+func normalize(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if n, ok := value.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return normalize(v.Elem().Interface())
+	}
+	return value
+}
+
+// value returns the operand's value, or false if a path operand matched
+// nothing.
+func (x jpOperand) value(e *jpEval, current node) (interface{}, bool) {
+	if x.isLiteral {
+		return x.literal, true
+	}
+	start := current
+	if x.absolute {
+		start = e.root
+	}
+	nodes := e.evalSteps([]node{start}, x.steps)
+	if len(nodes) == 0 {
+		return nil, false
+	}
+	if !nodes[0].v.IsValid() {
+		return nil, true
+	}
+	return unreflect(nodes[0].v), true
+}
+
+type jpEval struct {
+	walker
+	root node
+}
+
+func (e *jpEval) evalSteps(nodes []node, steps []jpStep) []node {
+	for _, step := range steps {
+		candidates := nodes
+		if step.recursive {
+			candidates = []node{}
+			for _, n := range nodes {
+				candidates = e.descendants(n, candidates, map[uintptr]struct{}{})
+			}
+		}
+		nodes = []node{}
+		for _, n := range candidates {
+			nodes = append(nodes, e.selectFrom(n, step)...)
+		}
+	}
+	return nodes
+}
+
+// descendants appends the node and everything beneath it in depth-first
+// order.  Pointers already on the current branch aren't revisited, so cyclic
+// structures terminate.
+func (e *jpEval) descendants(n node, out []node, seen map[uintptr]struct{}) []node {
+	out = append(out, n)
+	v := n.v
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return out
+		}
+		if v.Kind() == reflect.Ptr {
+			if _, ok := seen[v.Pointer()]; ok {
+				return out
+			}
+			seen[v.Pointer()] = struct{}{}
+			defer delete(seen, v.Pointer())
+		}
+		v = v.Elem()
+	}
+	for _, child := range e.children(n) {
+		out = e.descendants(child, out, seen)
+	}
+	return out
+}
+
+func (e *jpEval) selectFrom(n node, step jpStep) []node {
+	out := []node{}
+
+	switch step.kind {
+	case jpName:
+		for _, name := range step.names {
+			if child, ok := e.child(n, name); ok {
+				out = append(out, child)
+			}
+		}
+
+	case jpWildcard:
+		out = append(out, e.children(n)...)
+
+	case jpIndexes, jpSlice:
+		v := indirect(n.v)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return out
+		}
+		for _, i := range step.selectIndexes(v.Len()) {
+			out = append(out, e.element(n, v, i))
+		}
+
+	case jpFilter:
+		for _, child := range e.children(n) {
+			if step.filter.test(e, child) {
+				out = append(out, child)
+			}
+		}
+	}
+
+	return out
+}
+
+// selectIndexes resolves the index union or slice against a sequence of the
+// given length.
+func (step jpStep) selectIndexes(length int) []int {
+	out := []int{}
+	if step.kind == jpIndexes {
+		for _, i := range step.indexes {
+			if i < 0 {
+				i += length
+			}
+			if i >= 0 && i < length {
+				out = append(out, i)
+			}
+		}
+		return out
+	}
+
+	stride := 1
+	if step.slice[2] != nil {
+		stride = *step.slice[2]
+	}
+	if stride == 0 {
+		return out
+	}
+
+	bound := func(p *int, def int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += length
+		}
+		if i < 0 {
+			i = -1
+			if stride > 0 {
+				i = 0
+			}
+		}
+		if i > length {
+			i = length
+			if stride < 0 {
+				i = length - 1
+			}
+		}
+		return i
+	}
+
+	if stride > 0 {
+		for i, end := bound(step.slice[0], 0), bound(step.slice[1], length); i < end; i += stride {
+			out = append(out, i)
+		}
+	} else {
+		for i, end := bound(step.slice[0], length-1), bound(step.slice[1], -1); i > end; i += stride {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// jpParser is a recursive descent parser for JSONPath expressions.
+type jpParser struct {
+	expr string
+	pos  int
+}
+
+func (p *jpParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%v at offset %v: %s", ErrInvalidQuery, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *jpParser) parse() ([]jpStep, error) {
+	p.expr = strings.TrimSpace(p.expr)
+	if strings.HasPrefix(p.expr, "{") && strings.HasSuffix(p.expr, "}") {
+		p.expr = strings.TrimSpace(p.expr[1 : len(p.expr)-1])
+	}
+	steps := []jpStep{}
+	if p.peek() == '$' {
+		p.pos++
+	} else if p.pos < len(p.expr) && p.peek() != '.' && p.peek() != '[' {
+		// Allow a bare leading name, e.g. "items[0]".
+		name := p.name()
+		if name == "" {
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+		steps = append(steps, jpStep{kind: jpName, names: []string{name}})
+	}
+	rest, err := p.steps()
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, rest...)
+	if p.pos < len(p.expr) {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return steps, nil
+}
+
+func (p *jpParser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+func (p *jpParser) skipSpace() {
+	for p.pos < len(p.expr) && (p.expr[p.pos] == ' ' || p.expr[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// steps parses a sequence of "." and "[" prefixed steps, stopping at the
+// first character which can't begin a step.
+func (p *jpParser) steps() ([]jpStep, error) {
+	steps := []jpStep{}
+	for p.pos < len(p.expr) {
+		var step jpStep
+		switch {
+		case strings.HasPrefix(p.expr[p.pos:], ".."):
+			p.pos += 2
+			step.recursive = true
+			if p.peek() == '[' {
+				if err := p.bracket(&step); err != nil {
+					return nil, err
+				}
+			} else if err := p.dotted(&step); err != nil {
+				return nil, err
+			}
+
+		case p.peek() == '.':
+			p.pos++
+			if err := p.dotted(&step); err != nil {
+				return nil, err
+			}
+
+		case p.peek() == '[':
+			if err := p.bracket(&step); err != nil {
+				return nil, err
+			}
+
+		default:
+			return steps, nil
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// dotted parses the name or wildcard following a ".".
+func (p *jpParser) dotted(step *jpStep) error {
+	if p.peek() == '*' {
+		p.pos++
+		step.kind = jpWildcard
+		return nil
+	}
+	name := p.name()
+	if name == "" {
+		return p.errorf("expected name")
+	}
+	step.kind = jpName
+	step.names = []string{name}
+	return nil
+}
+
+// name consumes an unquoted name.
+func (p *jpParser) name() string {
+	start := p.pos
+	for p.pos < len(p.expr) && !strings.ContainsRune(".[]()=!<>&|'\" \t,*", rune(p.expr[p.pos])) {
+		p.pos++
+	}
+	return p.expr[start:p.pos]
+}
+
+// bracket parses a "[...]" selector.
+func (p *jpParser) bracket(step *jpStep) error {
+	p.pos++ // Opening bracket.
+	p.skipSpace()
+
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		step.kind = jpWildcard
+
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		if p.peek() != '(' {
+			return p.errorf("expected '(' after '?'")
+		}
+		p.pos++
+		expr, err := p.or()
+		if err != nil {
+			return err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return p.errorf("expected ')'")
+		}
+		p.pos++
+		step.kind = jpFilter
+		step.filter = expr
+
+	case c == '\'' || c == '"':
+		step.kind = jpName
+		for {
+			s, err := p.quoted()
+			if err != nil {
+				return err
+			}
+			step.names = append(step.names, s)
+			p.skipSpace()
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+			p.skipSpace()
+		}
+
+	default:
+		if err := p.indexes(step); err != nil {
+			return err
+		}
+	}
+
+	p.skipSpace()
+	if p.peek() != ']' {
+		return p.errorf("expected ']'")
+	}
+	p.pos++
+	return nil
+}
+
+// indexes parses an index union (e.g. "0,2") or a slice (e.g. "1:-1:2").
+func (p *jpParser) indexes(step *jpStep) error {
+	var (
+		parts = [3]*int{}
+		n     = 0
+	)
+	for {
+		p.skipSpace()
+		if i, ok := p.integer(); ok {
+			parts[n] = &i
+		}
+		p.skipSpace()
+		if p.peek() != ':' || n == 2 {
+			break
+		}
+		p.pos++
+		n++
+	}
+
+	if n > 0 {
+		step.kind = jpSlice
+		step.slice = parts
+		return nil
+	}
+
+	if parts[0] == nil {
+		return p.errorf("expected index")
+	}
+	step.kind = jpIndexes
+	step.indexes = []int{*parts[0]}
+	for p.peek() == ',' {
+		p.pos++
+		p.skipSpace()
+		i, ok := p.integer()
+		if !ok {
+			return p.errorf("expected index")
+		}
+		step.indexes = append(step.indexes, i)
+		p.skipSpace()
+	}
+	return nil
+}
+
+func (p *jpParser) integer() (int, bool) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	i, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false
+	}
+	return i, true
+}
+
+// quoted consumes a single or double quoted string, honoring backslash
+// escapes.
+func (p *jpParser) quoted() (string, error) {
+	var (
+		quote = p.peek()
+		buf   bytes.Buffer
+	)
+	p.pos++
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return buf.String(), nil
+		case c == '\\' && p.pos < len(p.expr):
+			buf.WriteByte(p.expr[p.pos])
+			p.pos++
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *jpParser) or() (jpExpr, error) {
+	l, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !strings.HasPrefix(p.expr[p.pos:], "||") {
+			return l, nil
+		}
+		p.pos += 2
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l = jpOr{l: l, r: r}
+	}
+}
+
+func (p *jpParser) and() (jpExpr, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !strings.HasPrefix(p.expr[p.pos:], "&&") {
+			return l, nil
+		}
+		p.pos += 2
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = jpAnd{l: l, r: r}
+	}
+}
+
+func (p *jpParser) unary() (jpExpr, error) {
+	p.skipSpace()
+	switch {
+	case p.peek() == '!' && !strings.HasPrefix(p.expr[p.pos:], "!="):
+		p.pos++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return jpNot{x: x}, nil
+
+	case p.peek() == '(':
+		p.pos++
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return x, nil
+	}
+
+	l, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.expr[p.pos:], op) {
+			p.pos += len(op)
+			p.skipSpace()
+			r, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return jpCompare{op: op, l: l, r: r}, nil
+		}
+	}
+	if l.isLiteral {
+		return nil, p.errorf("expected comparison")
+	}
+	return jpExists{operand: l}, nil
+}
+
+func (p *jpParser) operand() (jpOperand, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		steps, err := p.steps()
+		if err != nil {
+			return jpOperand{}, err
+		}
+		return jpOperand{absolute: c == '$', steps: steps}, nil
+
+	case c == '\'' || c == '"':
+		s, err := p.quoted()
+		if err != nil {
+			return jpOperand{}, err
+		}
+		return jpOperand{literal: s, isLiteral: true}, nil
+	}
+
+	word := p.name()
+	switch word {
+	case "true":
+		return jpOperand{literal: true, isLiteral: true}, nil
+	case "false":
+		return jpOperand{literal: false, isLiteral: true}, nil
+	case "null", "nil":
+		return jpOperand{literal: nil, isLiteral: true}, nil
+	}
+	// Names stop at ".", so pick up any fractional part of a number.
+	if p.peek() == '.' {
+		p.pos++
+		word += "." + p.name()
+	}
+	f, err := strconv.ParseFloat(word, 64)
+	if err != nil {
+		return jpOperand{}, p.errorf("unexpected operand %q", word)
+	}
+	return jpOperand{literal: f, isLiteral: true}, nil
+}
@@ -0,0 +1,220 @@
+package metaflector
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	QueryList struct {
+		Kind  string       `json:"kind"`
+		Items []*QueryItem `json:"items"`
+	}
+
+	QueryItem struct {
+		Name     string            `json:"name"`
+		Active   bool              `json:"active"`
+		Replicas int               `json:"replicas"`
+		Labels   map[string]string `json:"labels"`
+		Parent   *QueryItem        `json:"parent,omitempty"`
+	}
+)
+
+func TestQuery(t *testing.T) {
+	var (
+		r    = New(WithTagName("json"))
+		list = &QueryList{
+			Kind: "List",
+			Items: []*QueryItem{
+				{Name: "a", Active: true, Replicas: 3, Labels: map[string]string{"example.com/owner": "jay", "tier": "web"}},
+				{Name: "b", Active: false, Replicas: 1},
+				nil,
+				{Name: "c", Active: true, Replicas: 5, Labels: map[string]string{"tier": "db"}},
+			},
+		}
+	)
+
+	// Create a cycle to ensure recursive descent terminates.
+	list.Items[1].Parent = list.Items[1]
+
+	tests := []struct {
+		expr     string
+		expected []Match
+	}{
+		{
+			expr:     "$.kind",
+			expected: []Match{{Path: "kind", Value: "List"}},
+		},
+		{
+			expr:     "{.kind}",
+			expected: []Match{{Path: "kind", Value: "List"}},
+		},
+		{
+			expr:     "kind",
+			expected: []Match{{Path: "kind", Value: "List"}},
+		},
+		{
+			expr: "$.items[*].name",
+			expected: []Match{
+				{Path: "items[0].name", Value: "a"},
+				{Path: "items[1].name", Value: "b"},
+				{Path: "items[3].name", Value: "c"},
+			},
+		},
+		{
+			expr: "$.items[?(@.active==true)].name",
+			expected: []Match{
+				{Path: "items[0].name", Value: "a"},
+				{Path: "items[3].name", Value: "c"},
+			},
+		},
+		{
+			expr: "$.items[?(@.replicas > 1 && !@.parent)].name",
+			expected: []Match{
+				{Path: "items[0].name", Value: "a"},
+				{Path: "items[3].name", Value: "c"},
+			},
+		},
+		{
+			expr: `$.items[?(@.name == "b" || @.replicas >= 5)].replicas`,
+			expected: []Match{
+				{Path: "items[1].replicas", Value: int64(1)},
+				{Path: "items[3].replicas", Value: int64(5)},
+			},
+		},
+		{
+			expr: "$.items[?(@.labels.tier != 'web')].name",
+			expected: []Match{
+				{Path: "items[3].name", Value: "c"},
+			},
+		},
+		{
+			expr: "$.items[?(@.replicas < $.items[0].replicas)].name",
+			expected: []Match{
+				{Path: "items[1].name", Value: "b"},
+			},
+		},
+		{
+			expr:     "$.items[-1].name",
+			expected: []Match{{Path: "items[3].name", Value: "c"}},
+		},
+		{
+			expr: "$.items[0,3].name",
+			expected: []Match{
+				{Path: "items[0].name", Value: "a"},
+				{Path: "items[3].name", Value: "c"},
+			},
+		},
+		{
+			expr: "$.items[:2].name",
+			expected: []Match{
+				{Path: "items[0].name", Value: "a"},
+				{Path: "items[1].name", Value: "b"},
+			},
+		},
+		{
+			expr: "$.items[::-2].name",
+			expected: []Match{
+				{Path: "items[3].name", Value: "c"},
+				{Path: "items[1].name", Value: "b"},
+			},
+		},
+		{
+			expr: "$.items[0].labels['example.com/owner','tier']",
+			expected: []Match{
+				{Path: `items[0].labels["example.com/owner"]`, Value: "jay"},
+				{Path: "items[0].labels.tier", Value: "web"},
+			},
+		},
+		{
+			expr: "$.items[0].labels.*",
+			expected: []Match{
+				{Path: `items[0].labels["example.com/owner"]`, Value: "jay"},
+				{Path: "items[0].labels.tier", Value: "web"},
+			},
+		},
+		{
+			expr: "$..tier",
+			expected: []Match{
+				{Path: "items[0].labels.tier", Value: "web"},
+				{Path: "items[3].labels.tier", Value: "db"},
+			},
+		},
+		{
+			expr: "$..parent.name",
+			expected: []Match{
+				{Path: "items[1].parent.name", Value: "b"},
+				{Path: "items[1].parent.parent.name", Value: "b"},
+			},
+		},
+		{
+			expr:     "$.items[7].name",
+			expected: []Match{},
+		},
+		{
+			expr:     "$.missing",
+			expected: []Match{},
+		},
+	}
+
+	for i, test := range tests {
+		matches, err := r.Query(list, test.expr)
+		if err != nil {
+			t.Errorf("[i=%v] Unexpected error for expr=%q: %s", i, test.expr, err)
+			continue
+		}
+		if expected, actual := test.expected, matches; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected matches=%# v but actual=%# v for expr=%q", i, expected, actual, test.expr)
+		}
+	}
+
+	// Concrete paths may be fed straight back into Get.
+	matches, _ := r.Query(list, "$..name")
+	if expected, actual := 4, len(matches); actual != expected {
+		t.Fatalf("Expected %v matches but actual=%v: %# v", expected, actual, matches)
+	}
+	for i, match := range matches {
+		if expected, actual := match.Value, r.Get(list, match.Path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected value=%v but actual=%v for path=%q", i, expected, actual, match.Path)
+		}
+	}
+
+	// Numbers decoded with UseNumber compare as numbers.
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(`[{"id": 9007199254740993, "n": 3}, {"id": 1, "n": 10}]`))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	matches, _ = Query(doc, "$[?(@.n>5)].id")
+	if expected, actual := []Match{{Path: "[1].id", Value: json.Number("1")}}, matches; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected matches=%# v but actual=%# v", expected, actual)
+	}
+
+	// Field names default to the Go names.
+	matches, _ = Query(list, "$.Items[?(@.Active)].Name")
+	if expected, actual := []Match{{Path: "Items[0].Name", Value: "a"}, {Path: "Items[1].Name", Value: "b"}, {Path: "Items[3].Name", Value: "c"}}, matches; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected matches=%# v but actual=%# v", expected, actual)
+	}
+}
+
+func TestQueryInvalid(t *testing.T) {
+	tests := []string{
+		"$.",
+		"$.items[",
+		"$.items[?(@.a==)]",
+		"$.items[?(@.a==1]",
+		"$.items['unterminated]",
+		"$.items[x]",
+		"$.items]",
+		"$.items[?(1)]",
+	}
+
+	for i, expr := range tests {
+		if _, err := Query(Foo{}, expr); err == nil {
+			t.Errorf("[i=%v] Expected an error for expr=%q", i, expr)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package metaflector
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Merge overlays the non-zero values of src onto dst, which must be a non-nil
+// pointer to a value of the same type as src (or what src points to).
+//
+// By default non-zero src values replace those in dst, with structs, maps and
+// pointers to them merged recursively, so layering defaults, configuration
+// files and overrides is a matter of merging each onto the last.  Other
+// strategies may be chosen per path with WithMergeStrategy and
+// WithMergeByKey, e.g.
+//
+//	metaflector.Merge(&cfg, override,
+//		metaflector.WithMergeStrategy("Hosts", metaflector.MergeAppend),
+//		metaflector.WithMergeStrategy("Bar.Baz.Name", metaflector.MergeKeepDst),
+//		metaflector.WithMergeByKey("Bar.Baz.Contents", "Key"),
+//	)
+//
+// Values taken from src are deep copied, so dst never shares references with
+// src.  Unexported fields are left untouched.
+func Merge(dst interface{}, src interface{}, opts ...Option) error {
+	return defaultReflector.Merge(dst, src, opts...)
+}
+
+// Merge is the Reflector equivalent of the package-level Merge.
+func (r *Reflector) Merge(dst interface{}, src interface{}, opts ...Option) error {
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Ptr || d.IsNil() {
+		return ErrNotSettable
+	}
+	d = d.Elem()
+
+	s := reflect.ValueOf(src)
+	for s.Kind() == reflect.Ptr && s.Type() != d.Type() {
+		if s.IsNil() {
+			return nil
+		}
+		s = s.Elem()
+	}
+	if !s.IsValid() {
+		return nil
+	}
+	if s.Type() != d.Type() {
+		return ErrTypeMismatch
+	}
+
+	m := &merger{
+		r: r,
+		o: r.options(opts),
+		copier: &copier{
+			pointers: map[ref]reflect.Value{},
+			maps:     map[ref]reflect.Value{},
+			slices:   map[sliceRef]reflect.Value{},
+		},
+	}
+	for _, rule := range m.o.merges {
+		components, err := splitPath(rule.pattern, m.o.sep())
+		if err != nil {
+			components = strings.Split(rule.pattern, m.o.sep())
+		}
+		m.patterns = append(m.patterns, pattern(components))
+	}
+
+	return m.merge(d, s, location{}, mergeRule{strategy: MergeOverride})
+}
+
+type merger struct {
+	r        *Reflector
+	o        *options
+	copier   *copier
+	patterns []pattern // Compiled patterns of o.merges.
+}
+
+// rule returns the last merge rule matching the location, or else the
+// inherited one.
+func (m *merger) rule(l location, inherited mergeRule) mergeRule {
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if m.patterns[i].Match(l.plain) {
+			return m.o.merges[i]
+		}
+	}
+	return inherited
+}
+
+func (m *merger) merge(dst reflect.Value, src reflect.Value, l location, inherited mergeRule) error {
+	rule := m.rule(l, inherited)
+
+	if isEmpty(src) {
+		return nil
+	}
+	if rule.strategy == MergeKeepDst && !isEmpty(dst) && !isComposite(dst) {
+		return nil
+	}
+
+	// Rules carry over to everything beneath the path they match, except for
+	// merge-by-key which only makes sense for the slice it names.
+	childRule := rule
+	if rule.strategy == MergeByKey {
+		childRule = mergeRule{strategy: MergeOverride}
+	}
+
+	sep := m.o.sep()
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(m.copier.copy(src))
+			return nil
+		}
+		return m.merge(dst.Elem(), src.Elem(), l, rule)
+
+	case reflect.Interface:
+		if dst.IsNil() || dst.Elem().Type() != src.Elem().Type() {
+			dst.Set(m.copier.copy(src))
+			return nil
+		}
+		// Merge into a copy of the dynamic value and store it back.
+		elem := reflect.New(dst.Elem().Type()).Elem()
+		elem.Set(dst.Elem())
+		if err := m.merge(elem, src.Elem(), l, rule); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+
+	case reflect.Struct:
+		if isTerminalStruct(src.Type()) {
+			break
+		}
+		names := map[int]string{}
+		for _, f := range m.r.fields(src.Type(), m.o.tagName) {
+			names[f.index] = f.name
+		}
+		for i := 0; i < src.NumField(); i++ {
+			field := dst.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			name, ok := names[i]
+			if !ok {
+				name = src.Type().Field(i).Name
+			}
+			if err := m.merge(field, src.Field(i), l.child(name, sep), childRule); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(src.Type()))
+		}
+		for _, k := range src.MapKeys() {
+			elem := reflect.New(src.Type().Elem()).Elem()
+			if existing := dst.MapIndex(k); existing.IsValid() {
+				elem.Set(existing)
+			}
+			if err := m.merge(elem, src.MapIndex(k), l.child(keyName(k), sep), childRule); err != nil {
+				return err
+			}
+			dst.SetMapIndex(k, elem)
+		}
+		return nil
+
+	case reflect.Slice:
+		switch rule.strategy {
+		case MergeAppend:
+			dst.Set(reflect.AppendSlice(dst, m.copier.copy(src)))
+			return nil
+		case MergeByKey:
+			return m.mergeByKey(dst, src, l, rule.key)
+		case MergeKeepDst:
+			if dst.Len() > 0 {
+				return nil
+			}
+		}
+	}
+
+	dst.Set(m.copier.copy(src))
+	return nil
+}
+
+// mergeByKey merges each element of src into the element of dst with the same
+// key, appending those without a match.
+func (m *merger) mergeByKey(dst reflect.Value, src reflect.Value, l location, key string) error {
+	keyOf := func(v reflect.Value) (reflect.Value, error) {
+		elem := indirect(v)
+		if !elem.IsValid() {
+			return reflect.Value{}, nil
+		}
+		k := m.r.fieldByName(elem, key, m.o.tagName)
+		if !k.IsValid() || !k.CanInterface() {
+			return reflect.Value{}, ErrNotFound
+		}
+		return k, nil
+	}
+
+	sep := m.o.sep()
+
+	for i := 0; i < src.Len(); i++ {
+		sk, err := keyOf(src.Index(i))
+		if err != nil {
+			return &PathError{Path: l.index(i, sep).path, Err: err}
+		}
+		if !sk.IsValid() {
+			continue
+		}
+		matched := false
+		for j := 0; j < dst.Len() && !matched; j++ {
+			dk, err := keyOf(dst.Index(j))
+			if err != nil {
+				return &PathError{Path: l.index(j, sep).path, Err: err}
+			}
+			if dk.IsValid() && reflect.DeepEqual(dk.Interface(), sk.Interface()) {
+				if err := m.merge(dst.Index(j), src.Index(i), l.index(j, sep), mergeRule{strategy: MergeOverride}); err != nil {
+					return err
+				}
+				matched = true
+			}
+		}
+		if !matched {
+			dst.Set(reflect.Append(dst, m.copier.copy(src.Index(i))))
+		}
+	}
+
+	return nil
+}
+
+// isComposite returns true if v is, or points to, a struct or map whose
+// contents are merged individually.
+func isComposite(v reflect.Value) bool {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		return !isTerminalStruct(v.Type())
+	case reflect.Map:
+		return true
+	}
+	return false
+}
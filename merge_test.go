@@ -0,0 +1,170 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	defaults := func() *Foo {
+		return &Foo{
+			Bar: Bar{
+				Baz: Baz{
+					Name:       "default",
+					Multiplier: 1,
+					Contents: []Content{
+						{Key: "a", Value: "default-a", Version: 1},
+						{Key: "b", Value: "default-b", Version: 1},
+					},
+					Map:  map[string]string{"x": "1", "y": "2"},
+					PtrA: &uEight,
+				},
+				Stock: "default",
+			},
+		}
+	}
+
+	var (
+		eight = uint8(8)
+		b     = int64(2)
+	)
+
+	override := &Foo{
+		Bar: Bar{
+			Baz: Baz{
+				Name: "override",
+				Contents: []Content{
+					{Key: "b", Version: 2},
+					{Key: "c", Value: "override-c"},
+				},
+				ContentPtrs: []*Content{{Key: "p"}},
+				Map:         map[string]string{"y": "20", "z": "30"},
+				PtrA:        &eight,
+				PtrB:        &b,
+			},
+		},
+		StructPtr: &Bar{Stock: "ptr"},
+	}
+
+	tests := []struct {
+		opts     []Option
+		expected func(f *Foo)
+	}{
+		{
+			expected: func(f *Foo) {
+				f.Bar.Baz.Name = "override"
+				f.Bar.Baz.Contents = []Content{
+					{Key: "b", Version: 2},
+					{Key: "c", Value: "override-c"},
+				}
+				f.Bar.Baz.ContentPtrs = []*Content{{Key: "p"}}
+				f.Bar.Baz.Map = map[string]string{"x": "1", "y": "20", "z": "30"}
+				f.Bar.Baz.PtrA = &eight
+				f.Bar.Baz.PtrB = &b
+				f.StructPtr = &Bar{Stock: "ptr"}
+			},
+		},
+		{
+			opts: []Option{
+				WithMergeStrategy("**", MergeKeepDst),
+			},
+			expected: func(f *Foo) {
+				f.Bar.Baz.ContentPtrs = []*Content{{Key: "p"}}
+				f.Bar.Baz.Map = map[string]string{"x": "1", "y": "2", "z": "30"}
+				f.Bar.Baz.PtrB = &b
+				f.StructPtr = &Bar{Stock: "ptr"}
+			},
+		},
+		{
+			opts: []Option{
+				WithMergeStrategy("Bar.Baz", MergeKeepDst),
+				WithMergeStrategy("Bar.Baz.Map", MergeOverride),
+				WithMergeStrategy("Bar.Baz.Contents", MergeAppend),
+			},
+			expected: func(f *Foo) {
+				f.Bar.Baz.Contents = append(f.Bar.Baz.Contents, override.Bar.Baz.Contents...)
+				f.Bar.Baz.ContentPtrs = []*Content{{Key: "p"}}
+				f.Bar.Baz.Map = map[string]string{"x": "1", "y": "20", "z": "30"}
+				f.Bar.Baz.PtrB = &b
+				f.StructPtr = &Bar{Stock: "ptr"}
+			},
+		},
+		{
+			opts: []Option{
+				WithMergeByKey("Bar.Baz.Contents", "Key"),
+				WithMergeByKey("Bar.Baz.ContentPtrs", "Key"),
+			},
+			expected: func(f *Foo) {
+				f.Bar.Baz.Name = "override"
+				f.Bar.Baz.Contents = []Content{
+					{Key: "a", Value: "default-a", Version: 1},
+					{Key: "b", Value: "default-b", Version: 2},
+					{Key: "c", Value: "override-c"},
+				}
+				f.Bar.Baz.ContentPtrs = []*Content{{Key: "p"}}
+				f.Bar.Baz.Map = map[string]string{"x": "1", "y": "20", "z": "30"}
+				f.Bar.Baz.PtrA = &eight
+				f.Bar.Baz.PtrB = &b
+				f.StructPtr = &Bar{Stock: "ptr"}
+			},
+		},
+	}
+
+	for i, test := range tests {
+		var (
+			dst      = defaults()
+			expected = defaults()
+		)
+		test.expected(expected)
+		if err := Merge(dst, override, test.opts...); err != nil {
+			t.Errorf("[i=%v] Unexpected error: %s", i, err)
+			continue
+		}
+		if equal, path := Equal(expected, dst); !equal {
+			t.Errorf("[i=%v] Expected merged=%+v but actual=%+v (differs at %q)", i, expected, dst, path)
+		}
+	}
+
+	// Values taken from src aren't shared with dst.
+	dst := defaults()
+	if err := Merge(dst, *override); err != nil {
+		t.Fatal(err)
+	}
+	if dst.StructPtr == override.StructPtr || dst.Bar.Baz.PtrB == override.Bar.Baz.PtrB || &dst.Bar.Baz.Contents[0] == &override.Bar.Baz.Contents[0] {
+		t.Errorf("Expected merged values to be copied from src")
+	}
+	if expected, actual := "2", defaults().Bar.Baz.Map["y"]; actual != expected {
+		t.Errorf("Expected defaults to be unaffected but y=%q", actual)
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	if expected, actual := ErrNotSettable, Merge(Foo{}, Foo{}); actual != expected {
+		t.Errorf("Expected err=%v but actual=%v", expected, actual)
+	}
+	if expected, actual := ErrTypeMismatch, Merge(&Foo{}, Bar{}); actual != expected {
+		t.Errorf("Expected err=%v but actual=%v", expected, actual)
+	}
+	if err := Merge(&Foo{}, (*Foo)(nil)); err != nil {
+		t.Errorf("Expected nil src to be a no-op but got %v", err)
+	}
+
+	var (
+		dst = &Foo{Contents: []Content{{Key: "a"}}}
+		src = &Foo{Contents: []Content{{Key: "a"}}}
+	)
+	err := Merge(dst, src, WithMergeByKey("Contents", "Missing"))
+	if pathErr, ok := err.(*PathError); !ok || pathErr.Path != "Contents[0]" || pathErr.Err != ErrNotFound {
+		t.Errorf("Expected not found error for missing key field but got %v", err)
+	}
+
+	// Interfaces holding maps are merged too.
+	m := map[string]interface{}{"a": map[string]interface{}{"x": 1}}
+	if err := Merge(&m, map[string]interface{}{"a": map[string]interface{}{"y": 2}, "b": 3}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"a": map[string]interface{}{"x": 1, "y": 2}, "b": 3}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected merged map=%v but actual=%v", expected, m)
+	}
+}
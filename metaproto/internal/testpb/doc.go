@@ -0,0 +1,4 @@
+// Package testpb contains the protobuf messages metaproto is tested against.
+package testpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative test.proto
@@ -0,0 +1,160 @@
+// Package metaproto makes Protocol Buffers messages navigable by metaflector
+// according to their descriptors rather than their generated Go structs.
+//
+// Reflecting over a generated struct directly turns up its plumbing (state,
+// sizeCache, unknownFields, and the XXX_ fields of older generated code), hides
+// oneofs behind wrapper interfaces, and names fields after their Go
+// identifiers.  A message wrapped with Wrap is seen by metaflector's functions
+// as having exactly the fields of its descriptor, named as they are in the
+// .proto file:
+//
+//	metaproto.TerminalFields(order)
+//	// Output: []string{"customer.name", "items.sku", "order_id", "voucher", ...}
+//	metaproto.Get(order, "items.sku")
+//	// Output: []interface{}{"a", "b"}
+//	metaproto.Get(order, "tags")
+//	// Output: []interface{}{"rush", "gift"}
+//
+// Members of oneofs are reported as fields of the message itself, as they are
+// by the proto JSON mapping.  Repeated fields are slices (of wrapped messages,
+// for repeated message fields), which Get returns as a []interface{} as it
+// does any other slice, unless metaflector.WithTypedSlices is given.  Map
+// fields are generic objects keyed by the formatted map key, e.g.
+// "contacts.7.name".  Enum values are reported by name.  Unset message fields
+// are treated as nil struct pointers are, so their fields aren't listed and
+// resolve to nil.
+package metaproto
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/gigawattio/metaflector"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Message is a protobuf message whose fields are supplied to metaflector by
+// its descriptor.  It implements metaflector.FieldLister and
+// metaflector.FieldGetter.
+//
+// MetaflectorField returns an unset message field as a typed (*Message)(nil)
+// rather than an untyped nil, so callers using it directly should check the
+// *Message itself instead of comparing the interface{} against nil.
+type Message struct {
+	m protoreflect.Message
+}
+
+// Wrap returns m as a Message, or nil if m is nil.
+func Wrap(m proto.Message) *Message {
+	if m == nil {
+		return nil
+	}
+	pm := m.ProtoReflect()
+	if !pm.IsValid() {
+		// e.g. a nil pointer to a generated message.
+		return nil
+	}
+	return &Message{m: pm}
+}
+
+// Proto returns the wrapped message.
+func (w *Message) Proto() proto.Message {
+	return w.m.Interface()
+}
+
+// MetaflectorFields implements metaflector.FieldLister, returning the proto
+// names of the message's fields in declaration order.
+func (w *Message) MetaflectorFields() []string {
+	fields := w.m.Descriptor().Fields()
+	names := make([]string, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		names = append(names, string(fields.Get(i).Name()))
+	}
+	return names
+}
+
+// MetaflectorField implements metaflector.FieldGetter, returning the value of
+// the field with the given proto name.  Unset message fields are returned as
+// (*Message)(nil).
+func (w *Message) MetaflectorField(name string) (interface{}, bool) {
+	fd := w.m.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return nil, false
+	}
+
+	switch {
+	case fd.IsMap():
+		return mapValue(fd, w.m.Get(fd).Map()), true
+
+	case fd.IsList():
+		return listValue(fd, w.m.Get(fd).List()), true
+
+	case fd.Message() != nil:
+		if !w.m.Has(fd) {
+			return (*Message)(nil), true
+		}
+		return &Message{m: w.m.Get(fd).Message()}, true
+	}
+
+	return scalar(fd, w.m.Get(fd)), true
+}
+
+// TerminalFields returns the terminal fields of m, named by the proto names
+// of its fields.  See metaflector.TerminalFields.
+func TerminalFields(m proto.Message, opts ...metaflector.Option) []string {
+	return metaflector.TerminalFields(Wrap(m), opts...)
+}
+
+// Get returns the value at the path of proto field names in m.  See
+// metaflector.Get.
+func Get(m proto.Message, path string, opts ...metaflector.Option) interface{} {
+	return metaflector.Get(Wrap(m), path, opts...)
+}
+
+// listValue converts a repeated field into a []*Message, or a slice of the Go
+// type of its scalar values.
+func listValue(fd protoreflect.FieldDescriptor, l protoreflect.List) interface{} {
+	if fd.Message() != nil {
+		out := make([]*Message, 0, l.Len())
+		for i := 0; i < l.Len(); i++ {
+			out = append(out, &Message{m: l.Get(i).Message()})
+		}
+		return out
+	}
+
+	out := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(scalar(fd, l.NewElement()))), 0, l.Len())
+	for i := 0; i < l.Len(); i++ {
+		out = reflect.Append(out, reflect.ValueOf(scalar(fd, l.Get(i))))
+	}
+	return out.Interface()
+}
+
+// mapValue converts a map field into a generic object.
+func mapValue(fd protoreflect.FieldDescriptor, m protoreflect.Map) map[string]interface{} {
+	var (
+		out   = make(map[string]interface{}, m.Len())
+		value = fd.MapValue()
+	)
+	m.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+		if value.Message() != nil {
+			out[k.String()] = &Message{m: v.Message()}
+		} else {
+			out[k.String()] = scalar(value, v)
+		}
+		return true
+	})
+	return out
+}
+
+// scalar converts a scalar value of the field into its Go value, naming enum
+// values (or numbering unknown ones) with strings.
+func scalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	if fd.Kind() == protoreflect.EnumKind {
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	}
+	return v.Interface()
+}
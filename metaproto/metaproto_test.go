@@ -0,0 +1,149 @@
+package metaproto
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gigawattio/metaflector"
+	"github.com/gigawattio/metaflector/metaproto/internal/testpb"
+	"google.golang.org/protobuf/proto"
+)
+
+func newOrder() *testpb.Order {
+	return &testpb.Order{
+		OrderId:  42,
+		Status:   testpb.Status_STATUS_SHIPPED,
+		Customer: &testpb.Customer{Name: "Ann", Email: "ann@example.com"},
+		Items: []*testpb.LineItem{
+			{Sku: "a", Quantity: 2, Price: 1.5},
+			{Sku: "b", Quantity: 1, Data: []byte{1}},
+		},
+		Tags:     []string{"rush", "gift"},
+		Counts:   map[string]int32{"boxes": 3, "example.com/pallets": 1},
+		Contacts: map[int32]*testpb.Customer{7: {Name: "Bob"}},
+		Payment:  &testpb.Order_Card{Card: &testpb.Card{Number: "4111", ExpiryYear: 2030}},
+		Note:     proto.String("leave at door"),
+	}
+}
+
+func TestTerminalFields(t *testing.T) {
+	tests := []struct {
+		msg      proto.Message
+		opts     []metaflector.Option
+		expected []string
+	}{
+		{
+			msg: newOrder(),
+			expected: []string{
+				"card.expiry_year",
+				"card.number",
+				"contacts.7.email",
+				"contacts.7.name",
+				"counts.boxes",
+				`counts["example.com/pallets"]`,
+				"customer.email",
+				"customer.name",
+				"items.data",
+				"items.price",
+				"items.quantity",
+				"items.sku",
+				"note",
+				"order_id",
+				"status",
+				"tags",
+				"voucher",
+			},
+		},
+		{
+			msg:      &testpb.Order{},
+			opts:     []metaflector.Option{metaflector.WithDeclarationOrder()},
+			expected: []string{"order_id", "status", "tags", "voucher", "note"},
+		},
+		{
+			msg:      newOrder(),
+			opts:     []metaflector.Option{metaflector.WithFilter("items/*", "card/*"), metaflector.WithSeparator("/")},
+			expected: []string{"card/expiry_year", "card/number", "items/data", "items/price", "items/quantity", "items/sku"},
+		},
+		{
+			msg:      (*testpb.Order)(nil),
+			expected: []string{},
+		},
+	}
+
+	for i, test := range tests {
+		if actual := TerminalFields(test.msg, test.opts...); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("[i=%v] Expected fields=%v but actual=%v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	order := newOrder()
+	order.Parent = &testpb.Order{OrderId: 41}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: "order_id", expected: int64(42)},
+		{path: "status", expected: "STATUS_SHIPPED"},
+		{path: "customer.name", expected: "Ann"},
+		{path: "items.sku", expected: []interface{}{"a", "b"}},
+		{path: "items.quantity", expected: []interface{}{uint64(2), uint64(1)}},
+		{path: "items[1].data", expected: []interface{}{byte(1)}},
+		{path: "tags", expected: []interface{}{"rush", "gift"}},
+		{path: "tags[1]", expected: "gift"},
+		// Map values are returned as they are from generic objects.
+		{path: "counts.boxes", expected: int32(3)},
+		{path: "contacts.7.name", expected: "Bob"},
+		{path: "card.expiry_year", expected: int64(2030)},
+		{path: "voucher", expected: ""},
+		{path: "note", expected: "leave at door"},
+		{path: "parent.order_id", expected: int64(41)},
+		{path: "parent.customer.name", expected: nil},
+		{path: "OrderId", expected: nil},
+		{path: "sizeCache", expected: nil},
+	}
+
+	for i, test := range tests {
+		if actual := Get(order, test.path); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("[i=%v path=%v] Expected value=%#v but actual=%#v", i, test.path, test.expected, actual)
+		}
+	}
+
+	if expected, actual := "7", Get(&testpb.Order{Status: 7}, "status"); actual != expected {
+		t.Errorf("Expected unknown enum value=%v but actual=%v", expected, actual)
+	}
+	if expected, actual := []string{"rush", "gift"}, Get(order, "tags", metaflector.WithTypedSlices()); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected typed value=%#v but actual=%#v", expected, actual)
+	}
+	if value, ok := Wrap(order).MetaflectorField("customer"); !ok || value == (*Message)(nil) {
+		t.Errorf("Expected a set message field to be wrapped but actual=%#v", value)
+	}
+	if value, ok := Wrap(&testpb.Order{}).MetaflectorField("customer"); !ok || value != (*Message)(nil) {
+		t.Errorf("Expected an unset message field to be a nil *Message but actual=%#v", value)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	order := newOrder()
+	if w := Wrap(order); w.Proto() != proto.Message(order) {
+		t.Errorf("Expected the wrapped message to be returned")
+	}
+	if w := Wrap(nil); w != nil {
+		t.Errorf("Expected a nil message to wrap as nil but actual=%v", w)
+	}
+
+	// Wrapped messages work anywhere within other values.
+	type holder struct {
+		Orders []*Message
+	}
+	h := holder{Orders: []*Message{Wrap(order), Wrap(&testpb.Order{OrderId: 1})}}
+	if expected, actual := []interface{}{int64(42), int64(1)}, metaflector.Get(h, "Orders.order_id"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	matches := metaflector.GetWithPaths(Wrap(order), "**.name")
+	if expected := []metaflector.Match{{Path: "customer.name", Value: "Ann"}, {Path: "contacts.7.name", Value: "Bob"}}; !reflect.DeepEqual(matches, expected) {
+		t.Errorf("Expected matches=%v but actual=%v", expected, matches)
+	}
+}
@@ -0,0 +1,297 @@
+package metaflector
+
+import (
+	"reflect"
+)
+
+// Option is a functional configuration option which alters how objects are
+// traversed.
+type Option func(*options)
+
+type options struct {
+	separator    string
+	tagName      string
+	declOrder    bool
+	maxDepth     int
+	patterns     []string
+	excludeTypes map[reflect.Type]struct{}
+	exact        bool // Disables fanning out over slices and arrays.
+	fanOut       FanOut
+	typedSlices  bool
+	strictKeys   bool
+	maxIndex     int
+	delimiter    string
+	envName      func(components []string) string
+	flagName     func(components []string) string
+	ignore       []string
+	tolerance    float64
+	nilIsEmpty   bool
+	merges       []mergeRule
+	zero         func(v reflect.Value) bool
+}
+
+// FanOut determines how Get collects the values found by fanning out over
+// nested slices and arrays.
+type FanOut int
+
+const (
+	// FanOutNested preserves the structure of nested slices and arrays,
+	// yielding a []interface{} for each level.
+	FanOutNested FanOut = iota
+
+	// FanOutFlat concatenates the values found at every level into a single
+	// []interface{}.
+	FanOutFlat
+)
+
+// WithFanOut selects how Get collects values from nested slices and arrays.
+func WithFanOut(mode FanOut) Option {
+	return func(o *options) {
+		o.fanOut = mode
+	}
+}
+
+// WithTypedSlices makes Get return a slice or array found at the end of a path
+// as-is (e.g. a []Content) instead of as a []interface{} of its elements.
+func WithTypedSlices() Option {
+	return func(o *options) {
+		o.typedSlices = true
+	}
+}
+
+// WithStrictKeys makes DecodeValues reject keys which don't name a field,
+// rather than ignoring them.
+func WithStrictKeys() Option {
+	return func(o *options) {
+		o.strictKeys = true
+	}
+}
+
+// WithMaxIndex sets the largest slice index DecodeValues will grow a slice to
+// reach, overriding the default of DefaultMaxIndex.
+func WithMaxIndex(n int) Option {
+	return func(o *options) {
+		o.maxIndex = n
+	}
+}
+
+// WithSeparator sets the delimiter placed between field names, overriding the
+// package-level Separator.
+func WithSeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithTagName names fields after the given struct tag (e.g. "json") instead of
+// the Go field name.  Fields tagged "-" are skipped, and fields without the
+// tag fall back to their Go name.
+func WithTagName(name string) Option {
+	return func(o *options) {
+		o.tagName = name
+	}
+}
+
+// WithDeclarationOrder returns paths depth-first in the order fields are
+// declared, as they'd appear in the source, rather than alphabetically.
+func WithDeclarationOrder() Option {
+	return func(o *options) {
+		o.declOrder = true
+	}
+}
+
+// WithMaxDepth limits traversal to paths containing at most n components.
+// Zero or negative values mean unlimited depth.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithFilter restricts traversal to paths matching the supplied glob patterns.
+//
+// "*" matches any run of characters within a single path component, and "**"
+// matches zero or more whole components.  Patterns prefixed with "!" exclude
+// matching paths along with everything beneath them.  When at least one
+// non-excluding pattern is given, only paths matching one of them are kept.
+//
+// e.g. WithFilter("Spec.**", "!Spec.Template.*")
+func WithFilter(patterns ...string) Option {
+	return func(o *options) {
+		o.patterns = append(o.patterns, patterns...)
+	}
+}
+
+// WithExcludedTypes skips fields of the given types, including pointers to and
+// slices or arrays of them.
+func WithExcludedTypes(types ...reflect.Type) Option {
+	return func(o *options) {
+		if o.excludeTypes == nil {
+			o.excludeTypes = map[reflect.Type]struct{}{}
+		}
+		for _, t := range types {
+			o.excludeTypes[t] = struct{}{}
+		}
+	}
+}
+
+// WithDelimiter sets the delimiter between slice elements when values are
+// parsed from a single string, e.g. by LoadEnv.  Defaults to ",".
+func WithDelimiter(delim string) Option {
+	return func(o *options) {
+		o.delimiter = delim
+	}
+}
+
+// WithEnvName sets the transform from a field's path components to the name
+// of its environment variable, excluding the prefix.  Defaults to EnvName.
+func WithEnvName(fn func(components []string) string) Option {
+	return func(o *options) {
+		o.envName = fn
+	}
+}
+
+// WithFlagName sets the transform from a field's path components to the name
+// of its command-line flag.  Defaults to the lower-cased path.
+func WithFlagName(fn func(components []string) string) Option {
+	return func(o *options) {
+		o.flagName = fn
+	}
+}
+
+// WithIgnore makes Equal disregard the values at paths matching any of the
+// glob patterns, which follow the same syntax as WithFilter.  Patterns may
+// include slice and array indexes (e.g. "Contents.0.Key" or "Contents.*.Key")
+// or omit them as TerminalFields does (e.g. "Contents.Key"), and ignoring a
+// path ignores everything beneath it.
+func WithIgnore(patterns ...string) Option {
+	return func(o *options) {
+		o.ignore = append(o.ignore, patterns...)
+	}
+}
+
+// WithFloatTolerance makes Equal consider floating point (and complex)
+// numbers equal when they differ by no more than epsilon.
+func WithFloatTolerance(epsilon float64) Option {
+	return func(o *options) {
+		o.tolerance = epsilon
+	}
+}
+
+// WithNilEqualsEmpty makes Equal consider nil slices and maps equal to empty
+// ones.
+func WithNilEqualsEmpty() Option {
+	return func(o *options) {
+		o.nilIsEmpty = true
+	}
+}
+
+// MergeStrategy determines how Merge combines the values found at a path.
+type MergeStrategy int
+
+const (
+	// MergeOverride replaces dst values with non-zero src values, merging
+	// structs and maps field by field and key by key.  This is the default.
+	MergeOverride MergeStrategy = iota
+
+	// MergeKeepDst only fills in dst values which are zero, leaving non-zero
+	// ones untouched.
+	MergeKeepDst
+
+	// MergeAppend appends src slices onto dst slices rather than replacing
+	// them.
+	MergeAppend
+
+	// MergeByKey matches up elements of slices of structs by a key field,
+	// merging matching elements and appending the rest.  Use WithMergeByKey to
+	// name the key.
+	MergeByKey
+)
+
+// mergeRule associates a strategy with the paths matching a glob pattern.
+type mergeRule struct {
+	pattern  string
+	strategy MergeStrategy
+	key      string
+}
+
+// WithMergeStrategy makes Merge use the strategy for paths matching the glob
+// pattern (as for WithFilter, without slice indexes) and, unless overridden
+// by a later rule, everything beneath them.  "**" applies the strategy
+// everywhere.
+func WithMergeStrategy(pattern string, strategy MergeStrategy) Option {
+	return func(o *options) {
+		o.merges = append(o.merges, mergeRule{pattern: pattern, strategy: strategy})
+	}
+}
+
+// WithMergeByKey makes Merge match up the elements of the slices of structs at
+// paths matching the glob pattern by the named key field, e.g.
+// WithMergeByKey("Bar.Baz.Contents", "Key").
+func WithMergeByKey(pattern string, key string) Option {
+	return func(o *options) {
+		o.merges = append(o.merges, mergeRule{pattern: pattern, strategy: MergeByKey, key: key})
+	}
+}
+
+// WithZero sets the definition of a zero value used by SetFields and
+// ZeroFields.  Defaults to IsZero; pass IsEmpty to also count empty slices,
+// maps and strings as zero.
+func WithZero(fn func(v reflect.Value) bool) Option {
+	return func(o *options) {
+		o.zero = fn
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// sep returns the configured separator, falling back to the package-level
+// Separator.
+func (o *options) sep() string {
+	if o.separator == "" {
+		return Separator
+	}
+	return o.separator
+}
+
+// maxIdx returns the configured maximum slice index for DecodeValues, falling
+// back to DefaultMaxIndex.
+func (o *options) maxIdx() int {
+	if o.maxIndex <= 0 {
+		return DefaultMaxIndex
+	}
+	return o.maxIndex
+}
+
+// delim returns the configured slice element delimiter, falling back to ",".
+func (o *options) delim() string {
+	if o.delimiter == "" {
+		return ","
+	}
+	return o.delimiter
+}
+
+// excludesType returns true if the type, or the element type it ultimately
+// points to or contains, has been excluded.
+func (o *options) excludesType(t reflect.Type) bool {
+	if len(o.excludeTypes) == 0 {
+		return false
+	}
+	for {
+		if _, ok := o.excludeTypes[t]; ok {
+			return true
+		}
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package metaflector
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPath is returned when a path cannot be parsed.
+var ErrInvalidPath = errors.New("invalid path syntax")
+
+// SplitPath breaks a path into its unquoted components using the package-level
+// Separator.
+//
+// Paths are a sequence of components joined by the separator, e.g.
+// "Bar.Baz.Name".  Components which would otherwise be ambiguous (i.e. they
+// contain the separator or an opening bracket) are written as a Go quoted
+// string inside of brackets, attached directly to the preceding component:
+//
+//	Labels["example.com/owner"].Value
+//
+// Numeric components select a single element when applied to a slice or
+// array, and may also be bracketed, e.g. "Contents[2].Key" is equivalent to
+// "Contents.2.Key".  A "*" component (or "[*]") explicitly fans out over every
+// element, e.g. "Contents[*].Key", which is otherwise implied when a field
+// name is applied to a slice or array.  Quoting suppresses the special meaning
+// of "*", "**" and "-" components, e.g. `Labels["*"]` names the map key "*".
+//
+// Empty components are ignored when resolving paths, except for quoted ones
+// (e.g. `Labels[""]`), which name empty map keys.  Names emitted by
+// TerminalFields and EachField are quoted as needed, and
+// SplitPath(JoinPath(components...)) round-trips any non-empty list of
+// components.
+func SplitPath(path string) ([]string, error) {
+	return defaultReflector.SplitPath(path)
+}
+
+// SplitPath is the Reflector equivalent of the package-level SplitPath.
+func (r *Reflector) SplitPath(path string) ([]string, error) {
+	return splitPath(path, r.options(nil).sep())
+}
+
+// JoinPath assembles components into a path using the package-level
+// Separator, quoting components as necessary.
+func JoinPath(components ...string) string {
+	return defaultReflector.JoinPath(components...)
+}
+
+// JoinPath is the Reflector equivalent of the package-level JoinPath.
+func (r *Reflector) JoinPath(components ...string) string {
+	var (
+		sep  = r.options(nil).sep()
+		path string
+	)
+	for i, c := range components {
+		if i == 0 {
+			path = quoteComponent(c, sep)
+		} else {
+			path = appendPath(path, quoteComponent(c, sep), sep)
+		}
+	}
+	return path
+}
+
+// Unquoted "*", "**" and "-" components are replaced by these markers when
+// resolving paths, leaving quoted components of the same names (e.g. `["*"]`)
+// to name literal keys.
+const (
+	anyElement    = "\x00*"
+	anyDescendant = "\x00**"
+	newElement    = "\x00-"
+)
+
+// reserved returns the marker for a plain component with special meaning, or
+// the name itself.
+func reserved(name string) string {
+	switch name {
+	case "*":
+		return anyElement
+	case "**":
+		return anyDescendant
+	case "-":
+		return newElement
+	}
+	return name
+}
+
+// quoteComponent returns the component in bracketed form if it would
+// otherwise be misinterpreted when parsed.
+func quoteComponent(name string, sep string) string {
+	if name == "" || reserved(name) != name || strings.Contains(name, sep) || strings.Contains(name, "[") {
+		return "[" + strconv.Quote(name) + "]"
+	}
+	return name
+}
+
+// appendPath appends an already quoted partial path onto the prefix.
+// Bracketed components attach directly to the prefix, while plain ones are
+// preceded by the separator.
+func appendPath(prefix string, partial string, sep string) string {
+	if strings.HasPrefix(partial, "[") {
+		return prefix + partial
+	}
+	return prefix + sep + partial
+}
+
+// childPath appends an already quoted component onto a concrete path, which
+// may be empty.
+func childPath(prefix string, component string, sep string) string {
+	if prefix == "" {
+		return component
+	}
+	return appendPath(prefix, component, sep)
+}
+
+// splitPath parses the path into its components.  Plain components follow the
+// same rules as strings.Split, so the empty path yields a single empty
+// component.
+func splitPath(path string, sep string) ([]string, error) {
+	return scanPath(path, sep, true)
+}
+
+// pathComponents parses the path into the components to resolve.  Empty
+// components left by stray separators (e.g. in "A..B" or "A.") are dropped,
+// whereas quoted ones (e.g. `A[""].B`) name empty keys.  Unquoted reserved
+// components are replaced by their markers (e.g. anyElement for "*").
+func pathComponents(path string, sep string) ([]string, error) {
+	return scanPath(path, sep, false)
+}
+
+// scanPath parses the path into its components.  raw paths keep empty plain
+// components and reserved names as they are.
+func scanPath(path string, sep string, raw bool) ([]string, error) {
+	if !strings.Contains(path, "[") {
+		components := strings.Split(path, sep)
+		if raw {
+			return components, nil
+		}
+		out := make([]string, 0, len(components))
+		for _, c := range components {
+			if c != "" {
+				out = append(out, reserved(c))
+			}
+		}
+		return out, nil
+	}
+
+	plain := func(c string) string {
+		if raw {
+			return c
+		}
+		return reserved(c)
+	}
+
+	var (
+		components = []string{}
+		cur        bytes.Buffer
+		closed     bool // True immediately after a bracketed component.
+	)
+
+	for i := 0; i < len(path); {
+		switch {
+		case strings.HasPrefix(path[i:], sep):
+			if !closed && (raw || cur.Len() > 0) {
+				components = append(components, plain(cur.String()))
+			}
+			cur.Reset()
+			closed = false
+			i += len(sep)
+
+		case path[i] == '[':
+			if cur.Len() > 0 {
+				components = append(components, plain(cur.String()))
+				cur.Reset()
+			}
+			name, n, err := parseBracket(path[i:])
+			if err != nil {
+				return nil, err
+			}
+			if !strings.HasPrefix(path[i:], `["`) {
+				name = plain(name)
+			}
+			components = append(components, name)
+			closed = true
+			i += n
+
+		default:
+			if closed {
+				return nil, ErrInvalidPath
+			}
+			cur.WriteByte(path[i])
+			i++
+		}
+	}
+
+	if !closed && (raw || cur.Len() > 0) {
+		components = append(components, plain(cur.String()))
+	}
+
+	return components, nil
+}
+
+// parseBracket parses a bracketed quoted component, index or wildcard from the
+// start of s, returning the unquoted name and the number of bytes consumed.
+func parseBracket(s string) (string, int, error) {
+	if strings.HasPrefix(s, "[*]") {
+		return "*", 3, nil
+	}
+	if end := strings.IndexByte(s, ']'); end > 1 && s[1] != '"' {
+		if _, ok := parseIndex(s[1:end]); ok {
+			return s[1:end], end + 1, nil
+		}
+	}
+	if len(s) < 2 || s[0] != '[' || s[1] != '"' {
+		return "", 0, ErrInvalidPath
+	}
+	for i := 2; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			if i+1 >= len(s) || s[i+1] != ']' {
+				return "", 0, ErrInvalidPath
+			}
+			name, err := strconv.Unquote(s[1 : i+1])
+			if err != nil {
+				return "", 0, ErrInvalidPath
+			}
+			return name, i + 2, nil
+		}
+	}
+	return "", 0, ErrInvalidPath
+}
+
+// parseIndex returns the slice or array index represented by the component,
+// if any.  Indexes are non-negative decimal integers without leading zeros.
+func parseIndex(name string) (int, bool) {
+	if name == "" || len(name) > 1 && name[0] == '0' {
+		return 0, false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < '0' || name[i] > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(name)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// indexComponent returns the bracketed form of a slice or array index.
+func indexComponent(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
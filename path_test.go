@@ -0,0 +1,198 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Labeled struct {
+	Owner  string            `json:"example.com/owner"`
+	Weird  string            `json:"a[b]"`
+	Plain  string            `json:"plain"`
+	Labels map[string]string `json:"labels"`
+	Nested *Labeled          `json:"nested.ptr"`
+}
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path       string
+		components []string
+		err        error
+	}{
+		{path: "", components: []string{""}},
+		{path: "Bar", components: []string{"Bar"}},
+		{path: "Bar.Baz.Name", components: []string{"Bar", "Baz", "Name"}},
+		{path: "Bar..Name", components: []string{"Bar", "", "Name"}},
+		{path: `Labels["example.com/owner"]`, components: []string{"Labels", "example.com/owner"}},
+		{path: `Labels["example.com/owner"].Value`, components: []string{"Labels", "example.com/owner", "Value"}},
+		{path: `["a.b"]["c.d"]`, components: []string{"a.b", "c.d"}},
+		{path: `["a.b"].`, components: []string{"a.b", ""}},
+		{path: `A.["b.c"]`, components: []string{"A", "b.c"}},
+		{path: `A["quote\"bracket]\\"]`, components: []string{"A", `quote"bracket]\`}},
+		{path: "Contents[*].Key", components: []string{"Contents", "*", "Key"}},
+		{path: "Grid[*][*]", components: []string{"Grid", "*", "*"}},
+		{path: `A["unterminated`, err: ErrInvalidPath},
+		{path: `A["x"]B`, err: ErrInvalidPath},
+		{path: `A[x]`, err: ErrInvalidPath},
+		{path: `A["x"`, err: ErrInvalidPath},
+	}
+
+	for i, test := range tests {
+		components, err := SplitPath(test.path)
+		if err != test.err {
+			t.Errorf("[i=%v] Expected err=%v but actual=%v for path=%q", i, test.err, err, test.path)
+		}
+		if expected, actual := test.components, components; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected components=%# v but actual=%# v for path=%q", i, expected, actual, test.path)
+		}
+	}
+}
+
+func TestJoinPathRoundTrip(t *testing.T) {
+	tests := []struct {
+		components []string
+		path       string
+	}{
+		{components: []string{""}, path: `[""]`},
+		{components: []string{"Bar", "Baz"}, path: "Bar.Baz"},
+		{components: []string{"Labels", "example.com/owner"}, path: `Labels["example.com/owner"]`},
+		{components: []string{"a.b", "", "c"}, path: `["a.b"][""].c`},
+		{components: []string{"x[0]", "y"}, path: `["x[0]"].y`},
+		{components: []string{"", "tab\there", `q"uote`}, path: `[""].tab	here.q"uote`},
+		{components: []string{"multi.dot.key", "multi.dot.key"}, path: `["multi.dot.key"]["multi.dot.key"]`},
+		{components: []string{"Labels", "*", "**", "-"}, path: `Labels["*"]["**"]["-"]`},
+		{components: []string{"**", "a*", "-b"}, path: `["**"].a*.-b`},
+	}
+
+	for i, test := range tests {
+		path := JoinPath(test.components...)
+		if expected, actual := test.path, path; actual != expected {
+			t.Errorf("[i=%v] Expected path=%q but actual=%q", i, expected, actual)
+		}
+		components, err := SplitPath(path)
+		if err != nil {
+			t.Errorf("[i=%v] Unexpected error splitting path=%q: %s", i, path, err)
+		}
+		if expected, actual := test.components, components; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected components=%# v but actual=%# v", i, expected, actual)
+		}
+	}
+
+	r := New(WithSeparator("/"))
+	if expected, actual := `Labels["example.com/owner"]/name`, r.JoinPath("Labels", "example.com/owner", "name"); actual != expected {
+		t.Errorf("Expected path=%q but actual=%q", expected, actual)
+	}
+	if expected, actual := "Labels/example.com", r.JoinPath("Labels", "example.com"); actual != expected {
+		t.Errorf("Expected path=%q but actual=%q", expected, actual)
+	}
+}
+
+func TestEscapedTerminalFieldsRoundTrip(t *testing.T) {
+	var (
+		r   = New(WithTagName("json"))
+		obj = &Labeled{
+			Owner: "jay",
+			Weird: "w",
+			Plain: "p",
+			Labels: map[string]string{
+				"example.com/owner": "gigawatt",
+			},
+			Nested: &Labeled{
+				Owner: "nested-jay",
+			},
+		}
+	)
+
+	expected := []string{
+		`["a[b]"]`,
+		`["example.com/owner"]`,
+		`["nested.ptr"].labels`,
+		`["nested.ptr"].plain`,
+		`["nested.ptr"]["a[b]"]`,
+		`["nested.ptr"]["example.com/owner"]`,
+		"labels",
+		"plain",
+	}
+
+	paths := r.TerminalFields(obj)
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, paths)
+	}
+
+	// Every emitted path must resolve back to the value it was derived from.
+	values := map[string]interface{}{
+		`["a[b]"]`:                            "w",
+		`["example.com/owner"]`:               "jay",
+		"labels":                              obj.Labels,
+		`["nested.ptr"]["a[b]"]`:              "",
+		`["nested.ptr"]["example.com/owner"]`: "nested-jay",
+		`["nested.ptr"].labels`:               map[string]string(nil),
+		`["nested.ptr"].plain`:                "",
+		"plain":                               "p",
+	}
+	for _, path := range paths {
+		if expected, actual := values[path], r.Get(obj, path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected value=%[1]T/%[1]v but actual=%[2]T/%[2]v for path=%q", expected, actual, path)
+		}
+		if err := r.Set(obj, path, values[path]); err != nil {
+			t.Errorf("Unexpected error setting path=%q: %s", path, err)
+		}
+	}
+
+	// Map keys are addressable with either syntax.
+	if expected, actual := "gigawatt", r.Get(obj, `labels["example.com/owner"]`); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	if expected, actual := interface{}(nil), r.Get(obj, `labels.example.com/owner`); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	if expected, actual := interface{}(nil), r.Get(obj, `labels["missing`); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+
+	// The filter understands escaped components too.
+	if expected, actual := []string{`["nested.ptr"]["example.com/owner"]`}, r.TerminalFields(obj, WithFilter(`*["example.com/owner"]`)); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+}
+
+func TestReservedKeysRoundTrip(t *testing.T) {
+	doc := map[string]interface{}{
+		"*":  "star",
+		"**": "stars",
+		"-":  "dash",
+		"x":  map[string]interface{}{"*": "nested"},
+	}
+
+	expected := []string{`["*"]`, `["**"]`, `["-"]`, `x["*"]`}
+	paths := TerminalFields(doc)
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, paths)
+	}
+
+	values := map[string]interface{}{
+		`["*"]`:  "star",
+		`["**"]`: "stars",
+		`["-"]`:  "dash",
+		`x["*"]`: "nested",
+	}
+	for _, path := range paths {
+		if expected, actual := values[path], Get(doc, path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected value=%v but actual=%v for path=%q", expected, actual, path)
+		}
+		if expected, actual := []Match{{Path: path, Value: values[path]}}, GetWithPaths(doc, path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected matches=%# v but actual=%# v for path=%q", expected, actual, path)
+		}
+		if err := Set(&doc, path, "set"); err != nil {
+			t.Errorf("Unexpected error setting path=%q: %s", path, err)
+		}
+		if expected, actual := "set", Get(doc, path); actual != expected {
+			t.Errorf("Expected value=%v but actual=%v for path=%q", expected, actual, path)
+		}
+	}
+
+	// Unquoted, the same components keep their special meaning.
+	if expected, actual := 6, len(Get(doc, "**").([]interface{})); actual != expected {
+		t.Errorf("Expected %v recursive matches but actual=%v", expected, actual)
+	}
+}
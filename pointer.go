@@ -0,0 +1,174 @@
+package metaflector
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ErrInvalidPointer is returned when a JSON Pointer cannot be parsed.
+var ErrInvalidPointer = errors.New("invalid JSON pointer")
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+	// jsonReflector resolves JSON Pointers against json tag names.
+	jsonReflector = New(WithTagName("json"))
+)
+
+// PathToPointer converts a path into an RFC 6901 JSON Pointer, e.g.
+// "Bar.Baz.Contents[0].Key" becomes "/Bar/Baz/Contents/0/Key".  The empty
+// path converts to the whole-document pointer "".
+func PathToPointer(path string) (string, error) {
+	return defaultReflector.PathToPointer(path)
+}
+
+// PathToPointer is the Reflector equivalent of the package-level
+// PathToPointer.
+func (r *Reflector) PathToPointer(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	components, err := r.SplitPath(path)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	for _, c := range components {
+		buf.WriteByte('/')
+		buf.WriteString(pointerEscaper.Replace(c))
+	}
+	return buf.String(), nil
+}
+
+// PointerToPath converts an RFC 6901 JSON Pointer into a path, e.g.
+// "/bar/baz/contents/0/key" becomes "bar.baz.contents[0].key".
+func PointerToPath(pointer string) (string, error) {
+	return defaultReflector.PointerToPath(pointer)
+}
+
+// PointerToPath is the Reflector equivalent of the package-level
+// PointerToPath.
+func (r *Reflector) PointerToPath(pointer string) (string, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return "", err
+	}
+	var (
+		sep  = r.options(nil).sep()
+		path string
+	)
+	for i, token := range tokens {
+		var c string
+		if index, ok := parseIndex(token); ok {
+			c = indexComponent(index)
+		} else {
+			c = quoteComponent(token, sep)
+		}
+		if i == 0 {
+			path = c
+		} else {
+			path = appendPath(path, c, sep)
+		}
+	}
+	return path, nil
+}
+
+// GetPointer resolves an RFC 6901 JSON Pointer against obj, matching struct
+// fields by their json tag names.  Unlike Get, the pointer must identify
+// exactly one value, so numeric tokens are required to traverse slices and
+// arrays.
+func GetPointer(obj interface{}, pointer string) (interface{}, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, &PathError{Path: pointer, Err: err}
+	}
+
+	v := reflect.ValueOf(obj)
+	for _, token := range tokens {
+		if v, err = pointerStep(v, token); err != nil {
+			return nil, &PathError{Path: pointer, Err: err}
+		}
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	return unreflect(v), nil
+}
+
+// SetPointer assigns value to the location identified by an RFC 6901 JSON
+// Pointer, matching struct fields by their json tag names.  The final token
+// "-" appends to a slice.  obj must be a non-nil pointer.
+func SetPointer(obj interface{}, pointer string, value interface{}) error {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return &PathError{Path: pointer, Err: err}
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return &PathError{Path: pointer, Err: ErrNotSettable}
+	}
+
+	o := jsonReflector.options(nil)
+	o.exact = true
+
+	if err := jsonReflector.set(v.Elem(), tokens, value, o); err != nil {
+		return &PathError{Path: pointer, Err: err}
+	}
+	return nil
+}
+
+// pointerTokens splits and unescapes the reference tokens of a JSON Pointer.
+func pointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if pointer[0] != '/' {
+		return nil, ErrInvalidPointer
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		// "~" may only appear as part of the "~0" and "~1" escapes.
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || token[j+1] != '0' && token[j+1] != '1') {
+				return nil, ErrInvalidPointer
+			}
+		}
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return tokens, nil
+}
+
+// pointerStep resolves a single JSON Pointer reference token against v.
+func pointerStep(v reflect.Value, token string) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, ErrNotFound
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if field := jsonReflector.fieldByName(v, token, "json"); field.IsValid() {
+			return field, nil
+		}
+
+	case reflect.Map:
+		if keyType := v.Type().Key(); keyType.Kind() == reflect.String {
+			if value := v.MapIndex(reflect.ValueOf(token).Convert(keyType)); value.IsValid() {
+				return value, nil
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if i, ok := parseIndex(token); ok && i < v.Len() {
+			return v.Index(i), nil
+		}
+	}
+
+	return reflect.Value{}, ErrNotFound
+}
@@ -0,0 +1,284 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+)
+
+type (
+	PointerDoc struct {
+		Bar      PointerBar        `json:"bar"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Items    []*PointerItem    `json:"items"`
+		Untagged string
+		Ignored  string `json:"-"`
+	}
+
+	PointerBar struct {
+		Baz PointerBaz `json:"baz"`
+	}
+
+	PointerBaz struct {
+		Contents []Content `json:"contents"`
+	}
+
+	PointerItem struct {
+		Name   string `json:"name"`
+		Active bool   `json:"active"`
+	}
+)
+
+func TestPointerConversion(t *testing.T) {
+	tests := []struct {
+		path    string
+		pointer string
+		// canonical is the path expected when converting back, when it differs
+		// from path.
+		canonical string
+	}{
+		{path: "", pointer: ""},
+		{path: "bar", pointer: "/bar"},
+		{path: "bar.baz.contents[0].key", pointer: "/bar/baz/contents/0/key"},
+		{path: "bar.baz.contents.0.key", pointer: "/bar/baz/contents/0/key", canonical: "bar.baz.contents[0].key"},
+		{path: `labels["example.com/owner"]`, pointer: "/labels/example.com~1owner"},
+		{path: "a~b", pointer: "/a~0b"},
+		{path: `[""]`, pointer: "/"},
+		{path: `a[""].b`, pointer: "/a//b"},
+		{path: "items[10][3]", pointer: "/items/10/3"},
+		{path: "items.01", pointer: "/items/01"},
+	}
+
+	for i, test := range tests {
+		pointer, err := PathToPointer(test.path)
+		if err != nil {
+			t.Errorf("[i=%v] Unexpected error converting path=%q: %s", i, test.path, err)
+		}
+		if expected, actual := test.pointer, pointer; actual != expected {
+			t.Errorf("[i=%v] Expected pointer=%q but actual=%q", i, expected, actual)
+		}
+		path, err := PointerToPath(test.pointer)
+		if err != nil {
+			t.Errorf("[i=%v] Unexpected error converting pointer=%q: %s", i, test.pointer, err)
+		}
+		expected := test.path
+		if test.canonical != "" {
+			expected = test.canonical
+		}
+		if actual := path; actual != expected {
+			t.Errorf("[i=%v] Expected path=%q but actual=%q", i, expected, actual)
+		}
+	}
+
+	for i, pointer := range []string{"bar", "/a~", "/a~2b"} {
+		if _, err := PointerToPath(pointer); err != ErrInvalidPointer {
+			t.Errorf("[i=%v] Expected err=%v but actual=%v for pointer=%q", i, ErrInvalidPointer, err, pointer)
+		}
+	}
+
+	if _, err := PathToPointer(`a["b`); err != ErrInvalidPath {
+		t.Errorf("Expected err=%v but actual=%v", ErrInvalidPath, err)
+	}
+
+	if expected, actual := "bar/baz/contents[0]/key", func() string { p, _ := New(WithSeparator("/")).PointerToPath("/bar/baz/contents/0/key"); return p }(); actual != expected {
+		t.Errorf("Expected path=%q but actual=%q", expected, actual)
+	}
+}
+
+func TestGetPointer(t *testing.T) {
+	doc := &PointerDoc{
+		Bar: PointerBar{
+			Baz: PointerBaz{
+				Contents: []Content{
+					{Key: "zero"},
+					{Key: "one", Version: 1},
+				},
+			},
+		},
+		Labels: map[string]string{
+			"example.com/owner": "jay",
+			"":                  "empty",
+		},
+		Items: []*PointerItem{
+			nil,
+			{Name: "second", Active: true},
+		},
+		Untagged: "u",
+		Ignored:  "i",
+	}
+
+	tests := []struct {
+		pointer  string
+		expected interface{}
+		err      error
+	}{
+		{pointer: "", expected: doc},
+		{pointer: "/bar/baz/contents/1/Key", expected: "one"},
+		{pointer: "/bar/baz/contents/1/Version", expected: int64(1)},
+		{pointer: "/bar/baz/contents/0", expected: Content{Key: "zero"}},
+		{pointer: "/labels/example.com~1owner", expected: "jay"},
+		{pointer: "/labels/", expected: "empty"},
+		{pointer: "/items/1/name", expected: "second"},
+		{pointer: "/items/1/active", expected: true},
+		{pointer: "/Untagged", expected: "u"},
+		{pointer: "/items/0/name", err: ErrNotFound},
+		{pointer: "/items/2", err: ErrNotFound},
+		{pointer: "/items/name", err: ErrNotFound},
+		{pointer: "/bar/baz/contents/01", err: ErrNotFound},
+		{pointer: "/Bar", err: ErrNotFound},
+		{pointer: "/Ignored", err: ErrNotFound},
+		{pointer: "/labels/missing", err: ErrNotFound},
+		{pointer: "bar", err: ErrInvalidPointer},
+	}
+
+	for i, test := range tests {
+		actual, err := GetPointer(doc, test.pointer)
+		if test.err == nil && err != nil {
+			t.Errorf("[i=%v] Unexpected error for pointer=%q: %s", i, test.pointer, err)
+		} else if test.err != nil {
+			if pathErr, ok := err.(*PathError); !ok || pathErr.Err != test.err {
+				t.Errorf("[i=%v] Expected err=%v but actual=%v for pointer=%q", i, test.err, err, test.pointer)
+			}
+		}
+		if expected := test.expected; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected value=%[2]T/%[2]v but actual=%[3]T/%[3]v for pointer=%q", i, expected, actual, test.pointer)
+		}
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		value    interface{}
+		expected *PointerDoc
+		err      error
+	}{
+		{
+			pointer:  "/bar/baz/contents/0/Key",
+			value:    "set",
+			expected: &PointerDoc{Bar: PointerBar{Baz: PointerBaz{Contents: []Content{{Key: "set"}}}}},
+		},
+		{
+			pointer:  "/bar/baz/contents/-",
+			value:    Content{Key: "appended"},
+			expected: &PointerDoc{Bar: PointerBar{Baz: PointerBaz{Contents: []Content{{}, {Key: "appended"}}}}},
+		},
+		{
+			pointer:  "/labels/example.com~1owner",
+			value:    "jay",
+			expected: &PointerDoc{Bar: PointerBar{Baz: PointerBaz{Contents: []Content{{}}}}, Labels: map[string]string{"example.com/owner": "jay"}},
+		},
+		{
+			pointer:  "/items/-/name",
+			value:    "new",
+			expected: &PointerDoc{Bar: PointerBar{Baz: PointerBaz{Contents: []Content{{}}}}, Items: []*PointerItem{{Name: "new"}}},
+		},
+		{
+			pointer:  "/bar/baz/contents/key",
+			value:    "fanned",
+			expected: &PointerDoc{Bar: PointerBar{Baz: PointerBaz{Contents: []Content{{}}}}},
+			err:      ErrNotFound,
+		},
+		{
+			pointer:  "/bar/baz/contents/1/key",
+			value:    "out of range",
+			expected: &PointerDoc{Bar: PointerBar{Baz: PointerBaz{Contents: []Content{{}}}}},
+			err:      ErrNotFound,
+		},
+		{
+			pointer:  "/Ignored",
+			value:    "x",
+			expected: &PointerDoc{Bar: PointerBar{Baz: PointerBaz{Contents: []Content{{}}}}},
+			err:      ErrNotFound,
+		},
+	}
+
+	for i, test := range tests {
+		doc := &PointerDoc{Bar: PointerBar{Baz: PointerBaz{Contents: []Content{{}}}}}
+		err := SetPointer(doc, test.pointer, test.value)
+		if test.err == nil && err != nil {
+			t.Errorf("[i=%v] Unexpected error for pointer=%q: %s", i, test.pointer, err)
+		} else if test.err != nil {
+			if pathErr, ok := err.(*PathError); !ok || pathErr.Err != test.err {
+				t.Errorf("[i=%v] Expected err=%v but actual=%v for pointer=%q", i, test.err, err, test.pointer)
+			}
+		}
+		if expected, actual := test.expected, doc; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected doc=%# v but actual=%# v", i, expected, actual)
+		}
+	}
+
+	if err := SetPointer(PointerDoc{}, "/Untagged", "x"); err == nil {
+		t.Errorf("Expected an error when passing a non-pointer")
+	}
+}
+
+func TestPointerEmptyKeys(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{
+			"":  map[string]interface{}{"b": "empty"},
+			"b": "direct",
+		},
+	}
+
+	if expected, actual := "empty", func() interface{} { v, _ := GetPointer(doc, "/a//b"); return v }(); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	path, _ := PointerToPath("/a//b")
+	if expected, actual := "empty", Get(doc, path); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v for path=%q", expected, actual, path)
+	}
+	// Stray separators still don't name empty keys.
+	if expected, actual := "direct", Get(doc, "a..b"); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+
+	if err := Set(&doc, path, "set"); err != nil {
+		t.Fatalf("Unexpected error setting path=%q: %s", path, err)
+	}
+	if err := SetPointer(&doc, "/a//c", "pointer"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := map[string]interface{}{"b": "set", "c": "pointer"}
+	if actual := doc["a"].(map[string]interface{})[""]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+}
+
+func TestGetIndex(t *testing.T) {
+	obj := &Foo{
+		Contents: []Content{
+			{Key: "zero"},
+			{Key: "one"},
+		},
+		Bar: Bar{
+			Baz: Baz{
+				ContentPtrs: []*Content{nil, {Key: "ptr"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: "Contents[1].Key", expected: "one"},
+		{path: "Contents.1.Key", expected: "one"},
+		{path: "Contents[0]", expected: Content{Key: "zero"}},
+		{path: "Contents[2].Key", expected: nil},
+		{path: "Bar.Baz.ContentPtrs[1].Key", expected: "ptr"},
+		{path: "Bar.Baz.ContentPtrs[0].Key", expected: nil},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.expected, Get(obj, test.path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected value=%[2]T/%[2]v but actual=%[3]T/%[3]v for path=%q", i, expected, actual, test.path)
+		}
+	}
+
+	if err := Set(obj, "Contents[1].Version", 5); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := []Content{{Key: "zero"}, {Key: "one", Version: 5}}, obj.Contents; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected contents=%# v but actual=%# v", expected, actual)
+	}
+}
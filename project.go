@@ -0,0 +1,360 @@
+package metaflector
+
+import (
+	"reflect"
+)
+
+// Project returns a copy of obj, of the same type, in which only the values
+// at the given paths are populated and everything else is left zero.  This is
+// useful for field selection, e.g. trimming an API response down to what the
+// client asked for.
+//
+// Paths follow the same syntax as Get.  Pointers, maps and slices leading to
+// the selected values are allocated in the copy only where they're non-nil in
+// obj, and slices keep the same length, so paths which fan out (e.g.
+// "Contents.Key") populate every element while indexes (e.g. "Contents[1]")
+// populate only the one.  Paths passing through nil pointers or missing map
+// keys select nothing, whereas unknown fields result in ErrNotFound.
+//
+// Selected values are copied shallowly, so selecting a pointer, slice or map
+// shares whatever it references with obj.
+func Project(obj interface{}, paths ...string) (interface{}, error) {
+	return defaultReflector.Project(obj, paths...)
+}
+
+// Project is the Reflector equivalent of the package-level Project.
+func (r *Reflector) Project(obj interface{}, paths ...string) (interface{}, error) {
+	if obj == nil {
+		return nil, nil
+	}
+
+	var (
+		o   = r.options(nil)
+		src = reflect.ValueOf(obj)
+		dst = reflect.New(src.Type()).Elem()
+	)
+
+	if src.Kind() == reflect.Ptr && !src.IsNil() {
+		dst.Set(reflect.New(src.Type().Elem()))
+	}
+
+	for _, path := range paths {
+		components, err := pathComponents(path, o.sep())
+		if err != nil {
+			return nil, &PathError{Path: path, Err: err}
+		}
+		if err := r.project(dst, src, components, o); err != nil {
+			return nil, &PathError{Path: path, Err: err}
+		}
+	}
+
+	return dst.Interface(), nil
+}
+
+func (r *Reflector) project(dst reflect.Value, src reflect.Value, components []string, o *options) error {
+	if len(components) == 0 {
+		dst.Set(src)
+		return nil
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(src.Type().Elem()))
+		}
+		return r.project(dst.Elem(), src.Elem(), components, o)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return nil
+		}
+		// Project into a copy of the dynamic value, which may already hold
+		// the results of other paths.
+		elem := reflect.New(src.Elem().Type()).Elem()
+		if !dst.IsNil() && dst.Elem().Type() == elem.Type() {
+			elem.Set(dst.Elem())
+		}
+		if err := r.project(elem, src.Elem(), components, o); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+
+	case reflect.Struct:
+		index, ok := r.fieldIndex(src.Type(), components[0], o.tagName)
+		if !ok {
+			return ErrNotFound
+		}
+		// Promoted fields are reached through their embedded structs one at
+		// a time, allocating embedded pointers in dst as src's are followed.
+		last := len(index) - 1
+		for _, i := range index[:last] {
+			src, dst = src.Field(i), dst.Field(i)
+			if src.Kind() != reflect.Ptr {
+				continue
+			}
+			if src.IsNil() {
+				return nil
+			}
+			if dst.IsNil() {
+				if !dst.CanSet() {
+					return ErrNotSettable
+				}
+				dst.Set(reflect.New(src.Type().Elem()))
+			}
+			src, dst = src.Elem(), dst.Elem()
+		}
+		field := dst.Field(index[last])
+		if !field.CanSet() {
+			return ErrNotSettable
+		}
+		return r.project(field, src.Field(index[last]), components[1:], o)
+
+	case reflect.Map:
+		keyType := src.Type().Key()
+		if keyType.Kind() != reflect.String {
+			return ErrNotFound
+		}
+		key := reflect.ValueOf(components[0]).Convert(keyType)
+		value := src.MapIndex(key)
+		if !value.IsValid() {
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(src.Type()))
+		}
+		elem := reflect.New(src.Type().Elem()).Elem()
+		if existing := dst.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := r.project(elem, value, components[1:], o); err != nil {
+			return err
+		}
+		dst.SetMapIndex(key, elem)
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if src.Kind() == reflect.Slice {
+			if src.IsNil() {
+				return nil
+			}
+			if dst.Len() != src.Len() {
+				dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+			}
+		}
+		if i, isIndex := parseIndex(components[0]); isIndex {
+			if i >= src.Len() {
+				return nil
+			}
+			return r.project(dst.Index(i), src.Index(i), components[1:], o)
+		}
+		rest := components
+		if components[0] == anyElement {
+			rest = components[1:]
+		}
+		for i := 0; i < src.Len(); i++ {
+			if err := r.project(dst.Index(i), src.Index(i), rest, o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return ErrNotFound
+}
+
+// ProjectMap is like Project, but returns the selected values as nested
+// map[string]interface{} and []interface{} values keyed by field name (which
+// honors WithTagName), suitable for encoding as JSON.  Selected structs,
+// maps and slices are converted likewise in their entirety.
+//
+// obj must be a struct, a map with string keys, or a pointer to either.
+func ProjectMap(obj interface{}, paths ...string) (map[string]interface{}, error) {
+	return defaultReflector.ProjectMap(obj, paths...)
+}
+
+// ProjectMap is the Reflector equivalent of the package-level ProjectMap.
+func (r *Reflector) ProjectMap(obj interface{}, paths ...string) (map[string]interface{}, error) {
+	var (
+		o   = r.options(nil)
+		src = reflect.ValueOf(obj)
+		out = map[string]interface{}{}
+	)
+
+	if kind := indirect(src).Kind(); kind != reflect.Struct && kind != reflect.Map {
+		return nil, ErrTypeMismatch
+	}
+
+	for _, path := range paths {
+		components, err := pathComponents(path, o.sep())
+		if err != nil {
+			return nil, &PathError{Path: path, Err: err}
+		}
+		if len(components) == 0 {
+			continue
+		}
+		if _, err := r.projectMap(out, src, components, o); err != nil {
+			return nil, &PathError{Path: path, Err: err}
+		}
+	}
+
+	return out, nil
+}
+
+// projectMap merges the values selected from src into dst, which holds the
+// results of previous paths (if any), returning the result.
+func (r *Reflector) projectMap(dst interface{}, src reflect.Value, components []string, o *options) (interface{}, error) {
+	if len(components) == 0 {
+		return r.toMap(src, o, nil), nil
+	}
+
+	if src.Kind() == reflect.Ptr || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return dst, nil
+		}
+		return r.projectMap(dst, src.Elem(), components, o)
+	}
+
+	var value reflect.Value
+
+	switch src.Kind() {
+	case reflect.Struct:
+		if value = r.fieldByName(src, components[0], o.tagName); !value.IsValid() {
+			return nil, ErrNotFound
+		}
+		if !value.CanInterface() {
+			return nil, ErrNotSettable
+		}
+
+	case reflect.Map:
+		keyType := src.Type().Key()
+		if keyType.Kind() != reflect.String {
+			return nil, ErrNotFound
+		}
+		if value = src.MapIndex(reflect.ValueOf(components[0]).Convert(keyType)); !value.IsValid() {
+			return dst, nil
+		}
+
+	case reflect.Slice, reflect.Array:
+		if src.Kind() == reflect.Slice && src.IsNil() {
+			return dst, nil
+		}
+		list, ok := dst.([]interface{})
+		if !ok || len(list) != src.Len() {
+			list = make([]interface{}, src.Len())
+		}
+		if i, isIndex := parseIndex(components[0]); isIndex {
+			if i >= src.Len() {
+				return list, nil
+			}
+			elem, err := r.projectMap(list[i], src.Index(i), components[1:], o)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+			return list, nil
+		}
+		rest := components
+		if components[0] == anyElement {
+			rest = components[1:]
+		}
+		for i := range list {
+			elem, err := r.projectMap(list[i], src.Index(i), rest, o)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+		}
+		return list, nil
+
+	default:
+		return nil, ErrNotFound
+	}
+
+	m, ok := dst.(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+	}
+	child, err := r.projectMap(m[components[0]], value, components[1:], o)
+	if err != nil {
+		return nil, err
+	}
+	m[components[0]] = child
+	return m, nil
+}
+
+// toMap converts v into nested map[string]interface{} and []interface{}
+// values.  Pointers already present amongst the ancestors are converted to
+// nil, so cyclic structures terminate.
+func (r *Reflector) toMap(v reflect.Value, o *options, ancestors []uintptr) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr {
+			if containsPointer(ancestors, v.Pointer()) {
+				return nil
+			}
+			ancestors = append(ancestors[:len(ancestors):len(ancestors)], v.Pointer())
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if !isTerminalStruct(v.Type()) {
+			m := map[string]interface{}{}
+			for _, f := range r.fields(v.Type(), o.tagName) {
+				m[f.name] = r.toMap(v.Field(f.index), o, ancestors)
+			}
+			return m
+		}
+
+	case reflect.Map:
+		if v.Type().Key().Kind() == reflect.String && !v.IsNil() {
+			m := map[string]interface{}{}
+			for _, k := range v.MapKeys() {
+				m[k.String()] = r.toMap(v.MapIndex(k), o, ancestors)
+			}
+			return m
+		}
+
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() != reflect.Uint8 && !(v.Kind() == reflect.Slice && v.IsNil()) {
+			list := make([]interface{}, v.Len())
+			for i := range list {
+				list[i] = r.toMap(v.Index(i), o, ancestors)
+			}
+			return list
+		}
+	}
+
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// isTerminalStruct returns true for struct types which represent a single
+// value, such as time.Time, and so are kept intact when converted to maps.
+func isTerminalStruct(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+// fieldIndex returns the index sequence of the struct field with the given
+// name, as used by reflect.Value.FieldByIndex.
+func (r *Reflector) fieldIndex(t reflect.Type, name string, tagName string) ([]int, bool) {
+	if tagName == "" {
+		sf, ok := t.FieldByName(name)
+		return sf.Index, ok
+	}
+	for _, f := range r.fields(t, tagName) {
+		if f.name == name {
+			return []int{f.index}, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,181 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProject(t *testing.T) {
+	obj := &Foo{
+		Bar: Bar{
+			Baz: Baz{
+				Name:       "baz",
+				Multiplier: 1.5,
+				Contents: []Content{
+					{Key: "c0", Value: "v0", Version: 1},
+					{Key: "c1", Value: "v1", Version: 2},
+				},
+				ContentPtrs: []*Content{
+					nil,
+					{Key: "p1", Value: "pv1"},
+				},
+				Map: map[string]string{
+					"a": "1",
+					"b": "2",
+				},
+				PtrA: &uEight,
+			},
+			Stock: "stock",
+		},
+		Contents: []Content{
+			{Key: "top", Value: "tv"},
+		},
+	}
+
+	tests := []struct {
+		paths    []string
+		expected interface{}
+	}{
+		{
+			paths: []string{"Bar.Baz.Name", "Bar.Stock"},
+			expected: &Foo{
+				Bar: Bar{
+					Baz:   Baz{Name: "baz"},
+					Stock: "stock",
+				},
+			},
+		},
+		{
+			paths: []string{"Bar.Baz.Contents.Key", "Bar.Baz.Contents[1].Version", "Bar.Baz.ContentPtrs[*].Value"},
+			expected: &Foo{
+				Bar: Bar{
+					Baz: Baz{
+						Contents: []Content{
+							{Key: "c0"},
+							{Key: "c1", Version: 2},
+						},
+						ContentPtrs: []*Content{
+							nil,
+							{Value: "pv1"},
+						},
+					},
+				},
+			},
+		},
+		{
+			paths: []string{"Bar.Baz.Map.b", "Bar.Baz.Map.missing", "Bar.Baz.PtrA", "StructPtr.Stock"},
+			expected: &Foo{
+				Bar: Bar{
+					Baz: Baz{
+						Map:  map[string]string{"b": "2"},
+						PtrA: &uEight,
+					},
+				},
+			},
+		},
+		{
+			paths:    []string{"Contents"},
+			expected: &Foo{Contents: obj.Contents},
+		},
+		{
+			paths:    []string{},
+			expected: &Foo{},
+		},
+	}
+
+	for i, test := range tests {
+		actual, err := Project(obj, test.paths...)
+		if err != nil {
+			t.Errorf("[i=%v] Unexpected error: %s", i, err)
+			continue
+		}
+		if expected := test.expected; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected projection=%+v but actual=%+v for paths=%v", i, expected, actual, test.paths)
+		}
+	}
+
+	// Values are projected by value too.
+	if actual, err := Project(*obj, "Bar.Stock"); err != nil || !reflect.DeepEqual(actual, Foo{Bar: Bar{Stock: "stock"}}) {
+		t.Errorf("Expected value projection but actual=%+v err=%v", actual, err)
+	}
+
+	if _, err := Project(obj, "Bar.Missing"); err == nil || err.(*PathError).Err != ErrNotFound {
+		t.Errorf("Expected not found error but got %v", err)
+	}
+
+	// The original is left untouched.
+	if expected, actual := "baz", obj.Bar.Baz.Name; actual != expected {
+		t.Errorf("Expected original name=%q but actual=%q", expected, actual)
+	}
+
+	// Promoted fields of embedded pointers are allocated as needed.
+	type Embedded struct {
+		*Content
+		Name string
+	}
+	if actual, err := Project(&Embedded{Content: &Content{Key: "k", Value: "v"}}, "Key"); err != nil || !reflect.DeepEqual(actual, &Embedded{Content: &Content{Key: "k"}}) {
+		t.Errorf("Expected embedded projection but actual=%+v err=%v", actual, err)
+	}
+	if actual, err := Project(&Embedded{Name: "n"}, "Key", "Name"); err != nil || !reflect.DeepEqual(actual, &Embedded{Name: "n"}) {
+		t.Errorf("Expected nil embedded pointer to be skipped but actual=%+v err=%v", actual, err)
+	}
+}
+
+func TestProjectMap(t *testing.T) {
+	type (
+		Item struct {
+			ID      int       `json:"id"`
+			Name    string    `json:"name"`
+			Created time.Time `json:"created"`
+		}
+
+		Response struct {
+			Total int                    `json:"total"`
+			Items []*Item                `json:"items"`
+			Owner *Item                  `json:"owner"`
+			Meta  map[string]interface{} `json:"meta"`
+		}
+	)
+
+	created := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	resp := &Response{
+		Total: 2,
+		Items: []*Item{
+			{ID: 1, Name: "one", Created: created},
+			nil,
+		},
+		Owner: &Item{ID: 9, Name: "owner", Created: created},
+		Meta: map[string]interface{}{
+			"page": 1,
+			"next": map[string]interface{}{"cursor": "abc"},
+		},
+	}
+
+	r := New(WithTagName("json"))
+	actual, err := r.ProjectMap(resp, "total", "items.name", "items[0].id", "owner", "meta.next.cursor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"total": 2,
+		"items": []interface{}{
+			map[string]interface{}{"name": "one", "id": 1},
+			nil,
+		},
+		"owner": map[string]interface{}{"id": 9, "name": "owner", "created": created},
+		"meta": map[string]interface{}{
+			"next": map[string]interface{}{"cursor": "abc"},
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected map=%# v but actual=%# v", expected, actual)
+	}
+
+	if _, err := r.ProjectMap(resp, "items.Name"); err == nil {
+		t.Errorf("Expected error for Go field name when using json tag names")
+	}
+	if _, err := ProjectMap([]int{1}, "[0]"); err != ErrTypeMismatch {
+		t.Errorf("Expected err=%v but actual=%v", ErrTypeMismatch, err)
+	}
+}
@@ -0,0 +1,109 @@
+package metaflector
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Reflector carries the configuration used to traverse objects, such as the
+// path separator, field naming and depth limits, along with a cache of struct
+// field metadata.
+//
+// A Reflector is safe for concurrent use, and different Reflectors may be used
+// side-by-side with differing configurations.  The package-level functions are
+// backed by a default instance which honors the package-level Separator.
+type Reflector struct {
+	opts  []Option
+	mu    sync.RWMutex
+	cache map[fieldsKey][]field
+}
+
+// field describes an exported struct field which participates in traversal.
+type field struct {
+	index int
+	name  string
+	typ   reflect.Type
+}
+
+type fieldsKey struct {
+	typ     reflect.Type
+	tagName string
+}
+
+var defaultReflector = New()
+
+// New returns a Reflector which applies the given options to every call.
+// Options passed to individual method calls are layered on top of these.
+func New(opts ...Option) *Reflector {
+	return &Reflector{
+		opts:  opts,
+		cache: map[fieldsKey][]field{},
+	}
+}
+
+// options resolves the Reflector's options together with per-call options.
+func (r *Reflector) options(opts []Option) *options {
+	if len(opts) == 0 {
+		return newOptions(r.opts)
+	}
+	return newOptions(append(r.opts[:len(r.opts):len(r.opts)], opts...))
+}
+
+// fields returns the traversable fields of struct type t, named according to
+// the tag name (if any).
+func (r *Reflector) fields(t reflect.Type, tagName string) []field {
+	key := fieldsKey{typ: t, tagName: tagName}
+
+	r.mu.RLock()
+	fields, ok := r.cache[key]
+	r.mu.RUnlock()
+	if ok {
+		return fields
+	}
+
+	fields = []field{}
+	for i := 0; i < t.NumField(); i++ {
+		// Skip unexported (signaled by non-mepty pkgpath) or anonymous fields.
+		sf := t.Field(i)
+		if sf.PkgPath != "" || sf.Anonymous {
+			continue
+		}
+		name := sf.Name
+		if tagName != "" {
+			if tag := strings.Split(sf.Tag.Get(tagName), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, field{
+			index: i,
+			name:  name,
+			typ:   sf.Type,
+		})
+	}
+
+	r.mu.Lock()
+	r.cache[key] = fields
+	r.mu.Unlock()
+
+	return fields
+}
+
+// fieldByName returns the struct field of v with the given name, or the zero
+// Value if no such field exists.
+func (r *Reflector) fieldByName(v reflect.Value, name string, tagName string) reflect.Value {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	if tagName == "" {
+		return v.FieldByName(name)
+	}
+	for _, f := range r.fields(v.Type(), tagName) {
+		if f.name == name {
+			return v.Field(f.index)
+		}
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,130 @@
+package metaflector
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type Tagged struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name,omitempty"`
+	Secret   string   `json:"-"`
+	Untagged int      `yaml:"untagged"`
+	Inner    *Content `json:"inner"`
+}
+
+func TestReflectorSeparator(t *testing.T) {
+	var (
+		obj = Foo{
+			Bar: Bar{
+				Baz: Baz{
+					Name: "slashed",
+				},
+			},
+		}
+		slash = New(WithSeparator("/"), WithFilter("Bar/Baz/*"), WithMaxDepth(3))
+		dot   = New()
+		wg    sync.WaitGroup
+	)
+
+	// Reflectors with different separators must not interfere with each
+	// other.
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			expected := []string{"Bar/Baz/Active", "Bar/Baz/Map", "Bar/Baz/Multiplier", "Bar/Baz/Name", "Bar/Baz/PtrA", "Bar/Baz/PtrB"}
+			if actual := slash.TerminalFields(obj); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+			}
+			if expected, actual := "slashed", slash.Get(obj, "Bar/Baz/Name"); actual != expected {
+				t.Errorf("Expected value=%v but actual=%v", expected, actual)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if expected, actual := "slashed", dot.Get(obj, "Bar.Baz.Name"); actual != expected {
+				t.Errorf("Expected value=%v but actual=%v", expected, actual)
+			}
+			if expected, actual := 7, len(dot.TerminalFields(obj)); actual != expected {
+				t.Errorf("Expected %v fields but actual=%v", expected, actual)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Per-call options are layered on top of the Reflector's.
+	if expected, actual := []string{"Bar/Baz/Name"}, slash.TerminalFields(obj, WithFilter("!**/*i*", "!**/Ptr?", "!**/Map")); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+}
+
+func TestReflectorPackageSeparator(t *testing.T) {
+	defer func(sep string) { Separator = sep }(Separator)
+
+	Separator = "::"
+
+	obj := Bar{Stock: "shares"}
+
+	if expected, actual := "shares", Get(obj, "Stock"); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	if expected, actual := []string{"Baz::Active", "Baz::Map"}, TerminalFields(obj, WithFilter("Baz::Active", "Baz::Map")); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+}
+
+func TestReflectorTagName(t *testing.T) {
+	var (
+		r   = New(WithTagName("json"))
+		obj = &Tagged{
+			ID:       "abc",
+			Name:     "tagged",
+			Secret:   "shh",
+			Untagged: 7,
+			Inner: &Content{
+				Key: "k",
+			},
+		}
+	)
+
+	expected := []string{"Untagged", "id", "inner.Key", "inner.Value", "inner.Version", "name"}
+	if actual := r.TerminalFields(obj); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+
+	names := []string{}
+	r.EachField(obj, func(_ interface{}, name string, _ reflect.Kind) {
+		names = append(names, name)
+	})
+	if expected := []string{"id", "name", "Untagged", "inner"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected names=%# v but actual=%# v", expected, names)
+	}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+	}{
+		{path: "id", expected: "abc"},
+		{path: "name", expected: "tagged"},
+		{path: "Untagged", expected: int64(7)},
+		{path: "inner.Key", expected: "k"},
+		{path: "ID", expected: nil},
+		{path: "Secret", expected: nil},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.expected, r.Get(obj, test.path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected value=%[2]T/%[2]v but actual=%[3]T/%[3]v for path=%q", i, expected, actual, test.path)
+		}
+	}
+
+	// Field naming can also be selected per call.
+	if expected, actual := []string{"ID", "Inner.Key", "Inner.Value", "Inner.Version", "Name", "Secret", "Untagged"}, TerminalFields(obj); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+	if expected, actual := []string{"ID", "Name", "Secret", "untagged"}, r.TerminalFields(obj, WithTagName("yaml"), WithFilter("!Inner")); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+}
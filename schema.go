@@ -0,0 +1,349 @@
+package metaflector
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// SchemaDialect identifies the JSON Schema draft emitted by JSONSchema.
+	SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+	// descriptionTag is the struct tag holding a field's schema description.
+	descriptionTag = "description"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema is a JSON Schema (draft 2020-12), as generated by JSONSchema.  It
+// marshals to JSON with encoding/json.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // A string, or []string when nullable.
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// JSONSchema generates a JSON Schema describing the JSON encoding of obj's
+// type, which may also be given directly as a reflect.Type.
+//
+// Fields are traversed by type and named after their "json" struct tags unless
+// WithTagName selects another.  Unlike EachField, which skips embedded
+// structs, the fields of embedded structs without a tag name are flattened
+// into their parent, with the same precedence as encoding/json gives them, so
+// that the schema matches the encoding.  Fields whose
+// tag lacks "omitempty" are listed as required, since encoding/json always
+// emits them, and descriptions are read from the "description" struct tag.
+//
+// Kinds map onto the corresponding schema types, with unsigned integers
+// given a minimum of 0.  Slices and arrays become arrays, maps become objects
+// with additionalProperties, and pointers become nullable.  Types implementing
+// encoding.TextMarshaler are strings (time.Time with the date-time format),
+// []byte is a base64 encoded string, and interfaces accept anything.
+// Recursive types refer back to themselves via $ref, using $defs for types
+// other than the root, keyed by their package-qualified names (e.g.
+// "example.com/tree.Node").
+func JSONSchema(obj interface{}) *Schema {
+	return defaultReflector.JSONSchema(obj)
+}
+
+// JSONSchema is the Reflector equivalent of the package-level JSONSchema.
+func (r *Reflector) JSONSchema(obj interface{}) *Schema {
+	t, ok := obj.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(obj)
+	}
+
+	o := r.options(nil)
+	if o.tagName == "" {
+		o.tagName = "json"
+	}
+
+	g := &schemaGenerator{
+		r:          r,
+		o:          o,
+		root:       indirectType(t),
+		stack:      map[reflect.Type]struct{}{},
+		referenced: map[reflect.Type]struct{}{},
+		defs:       map[string]*Schema{},
+	}
+
+	var s *Schema
+	if t != nil {
+		s = g.schema(t)
+	}
+	if s == nil {
+		s = &Schema{}
+	}
+	s.Schema = SchemaDialect
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+type schemaGenerator struct {
+	r          *Reflector
+	o          *options
+	root       reflect.Type
+	stack      map[reflect.Type]struct{} // Struct types being generated.
+	referenced map[reflect.Type]struct{} // Struct types referred to by $ref.
+	defs       map[string]*Schema
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		return nullable(g.schema(t.Elem()))
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		// Nil slices encode as null.
+		return nullable(&Schema{Type: "array", Items: g.schema(t.Elem())})
+
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: "array", Items: g.schema(t.Elem()), MinItems: &n, MaxItems: &n}
+
+	case reflect.Map:
+		// Nil maps encode as null.
+		return nullable(&Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())})
+
+	case reflect.Struct:
+		return g.object(t)
+
+	case reflect.Interface:
+		return &Schema{}
+	}
+
+	// Channels, functions and complex numbers can't be encoded.
+	return nil
+}
+
+// object generates the schema for struct type t, or a reference to it if t is
+// already being generated further up.
+func (g *schemaGenerator) object(t reflect.Type) *Schema {
+	if _, ok := g.stack[t]; ok {
+		if t == g.root {
+			return &Schema{Ref: "#"}
+		}
+		g.referenced[t] = struct{}{}
+		return &Schema{Ref: "#/$defs/" + pointerEscaper.Replace(defName(t))}
+	}
+	g.stack[t] = struct{}{}
+	defer delete(g.stack, t)
+
+	s := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+
+	for _, f := range g.properties(t) {
+		var (
+			sf       = f.sf
+			tagOpts  = strings.Split(sf.Tag.Get(g.o.tagName), ",")[1:]
+			property = g.schema(sf.Type)
+		)
+		if property == nil {
+			continue
+		}
+		if hasTagOption(tagOpts, "string") && isQuotable(sf.Type) {
+			property = &Schema{Type: "string"}
+		}
+		if description := sf.Tag.Get(descriptionTag); description != "" {
+			property.Description = description
+		}
+		s.Properties[f.name] = property
+		if !hasTagOption(tagOpts, "omitempty") {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+
+	if _, ok := g.referenced[t]; ok {
+		g.defs[defName(t)] = s
+	}
+	return s
+}
+
+// property is a field of a struct's encoding, possibly promoted from an
+// embedded struct.
+type property struct {
+	name   string
+	sf     reflect.StructField
+	depth  int  // Number of embedded structs the field is promoted through.
+	tagged bool // True if the name comes from a struct tag.
+}
+
+// properties returns the fields of struct type t as encoding/json encodes
+// them: the fields of embedded structs without a tag name are promoted, and of
+// several fields sharing a name, the shallowest wins, then the only tagged one
+// amongst those at that depth; otherwise they're all omitted.
+func (g *schemaGenerator) properties(t reflect.Type) []property {
+	var (
+		all    = g.embedded(t, 0, map[reflect.Type]struct{}{}, nil)
+		byName = map[string][]int{}
+		out    = []property{}
+	)
+	for i, p := range all {
+		byName[p.name] = append(byName[p.name], i)
+	}
+	for i, p := range all {
+		if dominant(all, byName[p.name]) == i {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// embedded appends the fields of struct type t, promoting those of embedded
+// structs.  seen holds the embedded struct types already being walked, so
+// that recursive embedding terminates.
+func (g *schemaGenerator) embedded(t reflect.Type, depth int, seen map[reflect.Type]struct{}, out []property) []property {
+	if _, ok := seen[t]; ok {
+		return out
+	}
+	seen[t] = struct{}{}
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			sf  = t.Field(i)
+			ft  = indirectType(sf.Type)
+			tag = strings.Split(sf.Tag.Get(g.o.tagName), ",")[0]
+		)
+		// Unexported embedded structs may still have exported fields.
+		if tag == "-" || sf.PkgPath != "" && (!sf.Anonymous || ft.Kind() != reflect.Struct) {
+			continue
+		}
+		if sf.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			out = g.embedded(ft, depth+1, seen, out)
+			continue
+		}
+		name := sf.Name
+		if tag != "" {
+			name = tag
+		}
+		out = append(out, property{name: name, sf: sf, depth: depth, tagged: tag != ""})
+	}
+	return out
+}
+
+// dominant returns the index within all of the field which takes the name
+// shared by the candidates, or -1 if none does.
+func dominant(all []property, candidates []int) int {
+	depth := all[candidates[0]].depth
+	for _, i := range candidates {
+		if all[i].depth < depth {
+			depth = all[i].depth
+		}
+	}
+	var shallowest, tagged []int
+	for _, i := range candidates {
+		if all[i].depth == depth {
+			shallowest = append(shallowest, i)
+			if all[i].tagged {
+				tagged = append(tagged, i)
+			}
+		}
+	}
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0]
+	case len(tagged) == 1:
+		return tagged[0]
+	}
+	return -1
+}
+
+// nullable allows s to be null as well.
+func nullable(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	if typ, ok := s.Type.(string); ok {
+		s.Type = []string{typ, "null"}
+		return s
+	}
+	if s.Type == nil && s.Ref == "" && s.AnyOf == nil && s.Properties == nil {
+		// Already accepts anything.
+		return s
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
+
+// indirectType returns the type t points to, through any number of pointers.
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// defName returns the $defs key for type t, qualified by its package path so
+// that types of the same name from different packages don't collide.
+func defName(t reflect.Type) string {
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+func hasTagOption(opts []string, option string) bool {
+	for _, opt := range opts {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuotable returns true if the ",string" json tag option applies to values
+// of type t.
+func isQuotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
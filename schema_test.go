@@ -0,0 +1,194 @@
+package metaflector
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type (
+		Item struct {
+			Name  string            `json:"name" description:"display name"`
+			Price float64           `json:"price,omitempty"`
+			Qty   uint              `json:"qty,string"`
+			Attrs map[string]string `json:"attrs,omitempty"`
+		}
+
+		Node struct {
+			Value    int     `json:"value"`
+			Children []*Node `json:"children,omitempty"`
+		}
+
+		Catalog struct {
+			ID       string      `json:"id"`
+			Items    []Item      `json:"items"`
+			Featured *Item       `json:"featured,omitempty"`
+			Updated  time.Time   `json:"updated"`
+			Addr     net.IP      `json:"addr,omitempty"`
+			Raw      []byte      `json:"raw,omitempty"`
+			Point    [2]float32  `json:"point"`
+			Extra    interface{} `json:"extra,omitempty"`
+			Tree     Node        `json:"tree"`
+			Skipped  string      `json:"-"`
+			Callback func()      `json:"callback,omitempty"`
+			private  string
+		}
+
+		Base struct {
+			ID   string `json:"id"`
+			Kind string `json:"kind"`
+		}
+
+		Meta struct {
+			Kind string `json:"kind"`
+			Note string `json:"note,omitempty"`
+		}
+
+		Named struct {
+			Value int `json:"value"`
+		}
+
+		Embedding struct {
+			Base
+			*Meta
+			Named `json:"named"`
+			ID    int `json:"id"`
+		}
+	)
+
+	tests := []struct {
+		obj       interface{}
+		reflector *Reflector
+		expected  string
+	}{
+		{
+			obj: Catalog{},
+			expected: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {
+					"addr": {"type": "string"},
+					"extra": {},
+					"featured": {
+						"type": ["object", "null"],
+						"properties": {
+							"attrs": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+							"name": {"type": "string", "description": "display name"},
+							"price": {"type": "number"},
+							"qty": {"type": "string"}
+						},
+						"required": ["name", "qty"]
+					},
+					"id": {"type": "string"},
+					"items": {
+						"type": ["array", "null"],
+						"items": {
+							"type": "object",
+							"properties": {
+								"attrs": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+								"name": {"type": "string", "description": "display name"},
+								"price": {"type": "number"},
+								"qty": {"type": "string"}
+							},
+							"required": ["name", "qty"]
+						}
+					},
+					"point": {"type": "array", "items": {"type": "number"}, "minItems": 2, "maxItems": 2},
+					"raw": {"type": "string", "contentEncoding": "base64"},
+					"tree": {
+						"type": "object",
+						"properties": {
+							"children": {"type": ["array", "null"], "items": {"anyOf": [{"$ref": "#/$defs/github.com~1gigawattio~1metaflector.Node"}, {"type": "null"}]}},
+							"value": {"type": "integer"}
+						},
+						"required": ["value"]
+					},
+					"updated": {"type": "string", "format": "date-time"}
+				},
+				"required": ["id", "items", "updated", "point", "tree"],
+				"$defs": {
+					"github.com/gigawattio/metaflector.Node": {
+						"type": "object",
+						"properties": {
+							"children": {"type": ["array", "null"], "items": {"anyOf": [{"$ref": "#/$defs/github.com~1gigawattio~1metaflector.Node"}, {"type": "null"}]}},
+							"value": {"type": "integer"}
+						},
+						"required": ["value"]
+					}
+				}
+			}`,
+		},
+		{
+			obj: reflect.TypeOf(&Node{}),
+			expected: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": ["object", "null"],
+				"properties": {
+					"children": {"type": ["array", "null"], "items": {"anyOf": [{"$ref": "#"}, {"type": "null"}]}},
+					"value": {"type": "integer"}
+				},
+				"required": ["value"]
+			}`,
+		},
+		{
+			obj:       Item{},
+			reflector: New(WithTagName("description")),
+			expected: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {
+					"Attrs": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+					"Price": {"type": "number"},
+					"Qty": {"type": "integer", "minimum": 0},
+					"display name": {"type": "string", "description": "display name"}
+				},
+				"required": ["display name", "Price", "Qty", "Attrs"]
+			}`,
+		},
+		{
+			// Embedded structs are flattened, with shallower and tagged fields
+			// taking precedence, and fields tied for a name omitted.
+			obj: Embedding{},
+			expected: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"named": {"type": "object", "properties": {"value": {"type": "integer"}}, "required": ["value"]},
+					"note": {"type": "string"}
+				},
+				"required": ["named", "id"]
+			}`,
+		},
+		{
+			obj:      nil,
+			expected: `{"$schema": "https://json-schema.org/draft/2020-12/schema"}`,
+		},
+	}
+
+	for i, test := range tests {
+		r := test.reflector
+		if r == nil {
+			r = defaultReflector
+		}
+		actual, err := json.Marshal(r.JSONSchema(test.obj))
+		if err != nil {
+			t.Errorf("[i=%v] Unexpected error: %s", i, err)
+			continue
+		}
+		expected := &bytes.Buffer{}
+		if err := json.Compact(expected, []byte(test.expected)); err != nil {
+			t.Fatalf("[i=%v] Invalid expected JSON: %s", i, err)
+		}
+		var a, e interface{}
+		json.Unmarshal(actual, &a)
+		json.Unmarshal(expected.Bytes(), &e)
+		if !reflect.DeepEqual(a, e) {
+			t.Errorf("[i=%v] Expected schema:\n%s\nbut actual:\n%s", i, expected, actual)
+		}
+	}
+}
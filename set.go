@@ -0,0 +1,233 @@
+package metaflector
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrNotFound is returned when a path does not resolve to a field or key.
+	ErrNotFound = errors.New("path not found")
+
+	// ErrNotSettable is returned when the destination cannot be modified, e.g.
+	// because a non-pointer was passed or the field is unexported.
+	ErrNotSettable = errors.New("value is not settable")
+
+	// ErrTypeMismatch is returned when a value cannot be assigned to the type
+	// found at the destination path.
+	ErrTypeMismatch = errors.New("type mismatch")
+)
+
+// PathError records an error and the path which caused it.
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Set assigns value to the field at the specified path, which is parsed the
+// same way as it is for Get.  obj must be a non-nil pointer.
+//
+// Nil pointers and maps encountered along the way are allocated, as are
+// nil interface{} values, which become map[string]interface{} so that generic
+// trees decoded from JSON or YAML can be grown the same way.  When the
+// path passes through a slice or array, the value is assigned to the
+// corresponding field of every non-nil element, unless the next component is
+// an index (selecting a single element) or "-" (appending a new element).
+// Numeric values are converted to the destination's numeric type, so values
+// returned by Get may be passed straight back in.  Objects implementing
+// StaticSetter assign values of the exact field type without reflection.
+func Set(obj interface{}, path string, value interface{}) error {
+	return defaultReflector.Set(obj, path, value)
+}
+
+// Set is the Reflector equivalent of the package-level Set.
+func (r *Reflector) Set(obj interface{}, path string, value interface{}) error {
+	o := r.options(nil)
+	components, err := pathComponents(path, o.sep())
+	if err != nil {
+		return &PathError{Path: path, Err: err}
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return &PathError{Path: path, Err: ErrNotSettable}
+	}
+
+	if s, ok := obj.(StaticSetter); ok && s.MetaflectorSet(path, value, o.sep(), o.tagName) {
+		return nil
+	}
+
+	if err := r.set(v.Elem(), components, value, o); err != nil {
+		return &PathError{Path: path, Err: err}
+	}
+	return nil
+}
+
+func (r *Reflector) set(v reflect.Value, components []string, value interface{}, o *options) error {
+	if len(components) == 0 {
+		return assign(v, value)
+	}
+
+	// Resolve pointers, allocating as necessary.
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return ErrNotSettable
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		field := r.fieldByName(v, components[0], o.tagName)
+		if !field.IsValid() {
+			return ErrNotFound
+		}
+		if !field.CanSet() {
+			return ErrNotSettable
+		}
+		return r.set(field, components[1:], value, o)
+
+	case reflect.Map:
+		key, existing := mapIndex(v, components[0])
+		if !key.IsValid() {
+			return ErrNotFound
+		}
+		if v.IsNil() {
+			if !v.CanSet() {
+				return ErrNotSettable
+			}
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		// Map elements aren't addressable, so modify a copy and store it back.
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := r.set(elem, components[1:], value, o); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+
+	case reflect.Interface:
+		if v.IsNil() {
+			// Grow generic trees (e.g. decoded JSON) with new objects, as
+			// nil maps are allocated in typed ones.
+			if _, isIndex := parseIndex(components[0]); isIndex || components[0] == newElement || components[0] == anyElement || v.NumMethod() > 0 || !v.CanSet() {
+				return ErrNotFound
+			}
+			v.Set(reflect.ValueOf(map[string]interface{}{}))
+		}
+		elem := v.Elem()
+		if elem.Kind() == reflect.Ptr {
+			return r.set(elem, components, value, o)
+		}
+		// Modify a copy of the dynamic value and store it back.
+		cp := reflect.New(elem.Type()).Elem()
+		cp.Set(elem)
+		if err := r.set(cp, components, value, o); err != nil {
+			return err
+		}
+		if !v.CanSet() {
+			return ErrNotSettable
+		}
+		v.Set(cp)
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if i, isIndex := parseIndex(components[0]); isIndex {
+			if i >= v.Len() {
+				return ErrNotFound
+			}
+			return r.set(v.Index(i), components[1:], value, o)
+		}
+		// JSON Pointers, resolved exactly, spell the append token plainly.
+		if (components[0] == newElement || o.exact && components[0] == "-") && v.Kind() == reflect.Slice {
+			// Append a new element, as in RFC 6901.
+			if !v.CanSet() {
+				return ErrNotSettable
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := r.set(elem, components[1:], value, o); err != nil {
+				return err
+			}
+			v.Set(reflect.Append(v, elem))
+			return nil
+		}
+		if o.exact {
+			return ErrNotFound
+		}
+		rest := components
+		if components[0] == anyElement {
+			rest = components[1:]
+		}
+		var err error
+		eachElement(v, func(_ int, ele reflect.Value) {
+			if err != nil || (ele.Kind() == reflect.Ptr || ele.Kind() == reflect.Interface) && ele.IsNil() {
+				return
+			}
+			err = r.set(ele, rest, value, o)
+		})
+		return err
+	}
+
+	return ErrNotFound
+}
+
+// assign stores value into v, converting between numeric types and wrapping
+// values in a pointer when v is a pointer to the value's type.
+func assign(v reflect.Value, value interface{}) error {
+	if !v.CanSet() {
+		return ErrNotSettable
+	}
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	x := reflect.ValueOf(value)
+
+	switch {
+	case x.Type().AssignableTo(v.Type()):
+		v.Set(x)
+
+	case v.Kind() == reflect.Ptr && (x.Type().AssignableTo(v.Type().Elem()) || convertible(x.Type(), v.Type().Elem())):
+		ptr := reflect.New(v.Type().Elem())
+		if err := assign(ptr.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(ptr)
+
+	case convertible(x.Type(), v.Type()):
+		v.Set(x.Convert(v.Type()))
+
+	default:
+		return ErrTypeMismatch
+	}
+	return nil
+}
+
+// convertible returns true if values of type from may be converted to type to
+// without changing their meaning, e.g. between numeric types or between
+// strings of differing named types.
+func convertible(from reflect.Type, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	return from.Kind() == to.Kind() || isNumeric(from.Kind()) && isNumeric(to.Kind())
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
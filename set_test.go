@@ -0,0 +1,150 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	type Holder struct {
+		Any    interface{}
+		Counts map[string]int
+		Names  [2]string
+	}
+
+	tests := []struct {
+		obj      interface{}
+		path     string
+		value    interface{}
+		expected interface{}
+		err      error
+	}{
+		{
+			obj:      &Content{},
+			path:     "Key",
+			value:    "k",
+			expected: &Content{Key: "k"},
+		},
+		{
+			obj:      &Content{},
+			path:     "Version",
+			value:    int64(7),
+			expected: &Content{Version: 7},
+		},
+		{
+			obj:      &Content{Key: "k"},
+			path:     "Key",
+			value:    nil,
+			expected: &Content{},
+		},
+		{
+			obj:      &Foo{},
+			path:     "StructPtr.Baz.Name",
+			value:    "allocated",
+			expected: &Foo{StructPtr: &Bar{Baz: Baz{Name: "allocated"}}},
+		},
+		{
+			obj:      &Foo{},
+			path:     "Bar.Baz.PtrA",
+			value:    uEight,
+			expected: &Foo{Bar: Bar{Baz: Baz{PtrA: &uEight}}},
+		},
+		{
+			obj:      &Foo{},
+			path:     "Bar.Baz.PtrB",
+			value:    3,
+			expected: &Foo{Bar: Bar{Baz: Baz{PtrB: &threeve}}},
+		},
+		{
+			obj:      &Foo{},
+			path:     "StructPtr",
+			value:    &Bar{Stock: "whole"},
+			expected: &Foo{StructPtr: &Bar{Stock: "whole"}},
+		},
+		{
+			obj:      &Foo{Contents: []Content{{Key: "a"}, {Key: "b"}}},
+			path:     "Contents.Version",
+			value:    2,
+			expected: &Foo{Contents: []Content{{Key: "a", Version: 2}, {Key: "b", Version: 2}}},
+		},
+		{
+			obj:      &Baz{ContentPtrs: []*Content{nil, {Key: "a"}}},
+			path:     "ContentPtrs.Value",
+			value:    "v",
+			expected: &Baz{ContentPtrs: []*Content{nil, {Key: "a", Value: "v"}}},
+		},
+		{
+			obj:      &Baz{},
+			path:     `Map["example.com/owner"]`,
+			value:    "jay",
+			expected: &Baz{Map: map[string]string{"example.com/owner": "jay"}},
+		},
+		{
+			obj:      &Holder{Any: map[string]interface{}{"a": map[string]interface{}{}}},
+			path:     "Any.a.b",
+			value:    true,
+			expected: &Holder{Any: map[string]interface{}{"a": map[string]interface{}{"b": true}}},
+		},
+		{
+			obj:      &Holder{Counts: map[string]int{"x": 1}},
+			path:     "Counts.y",
+			value:    2.0,
+			expected: &Holder{Counts: map[string]int{"x": 1, "y": 2}},
+		},
+		{
+			obj:      &Holder{},
+			path:     "Names",
+			value:    [2]string{"a", "b"},
+			expected: &Holder{Names: [2]string{"a", "b"}},
+		},
+		{
+			obj:      &Content{},
+			path:     "Missing",
+			value:    "x",
+			expected: &Content{},
+			err:      ErrNotFound,
+		},
+		{
+			obj:      &Content{},
+			path:     "Key",
+			value:    5,
+			expected: &Content{},
+			err:      ErrTypeMismatch,
+		},
+		{
+			obj:      &Baz{},
+			path:     "hiddenString",
+			value:    "x",
+			expected: &Baz{},
+			err:      ErrNotSettable,
+		},
+		{
+			obj:      Content{},
+			path:     "Key",
+			value:    "x",
+			expected: Content{},
+			err:      ErrNotSettable,
+		},
+		{
+			obj:      &Content{},
+			path:     `Key["x`,
+			value:    "x",
+			expected: &Content{},
+			err:      ErrInvalidPath,
+		},
+	}
+
+	for i, test := range tests {
+		err := Set(test.obj, test.path, test.value)
+		if test.err == nil && err != nil {
+			t.Errorf("[i=%v] Unexpected error: %s", i, err)
+		} else if test.err != nil {
+			if pathErr, ok := err.(*PathError); !ok || pathErr.Err != test.err || pathErr.Path != test.path {
+				t.Errorf("[i=%v] Expected err=%v but actual=%v", i, test.err, err)
+			}
+		}
+		if expected, actual := test.expected, test.obj; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected obj=%# v but actual=%# v", i, expected, actual)
+		}
+	}
+}
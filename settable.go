@@ -0,0 +1,219 @@
+package metaflector
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// setting is a terminal struct field whose value can be parsed from a string,
+// e.g. by LoadEnv.
+type setting struct {
+	path       string
+	components []string
+	field      reflect.StructField
+}
+
+// settings returns the fields beneath struct type t (or a pointer to one)
+// which parseValue can populate, in declaration order.  Nested structs are
+// descended into, including through nil pointers, and recursive types are
+// expanded only once per branch.
+func (r *Reflector) settings(t reflect.Type, o *options) []setting {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return []setting{}
+	}
+	includes, excludes := compilePatterns(o.patterns, o.sep())
+	return r.appendSettings([]setting{}, t, "", nil, o, includes, excludes, map[reflect.Type]struct{}{})
+}
+
+func (r *Reflector) appendSettings(out []setting, t reflect.Type, path string, components []string, o *options, includes []pattern, excludes []pattern, seen map[reflect.Type]struct{}) []setting {
+	if _, ok := seen[t]; ok {
+		return out
+	}
+	seen[t] = struct{}{}
+	defer delete(seen, t)
+
+	sep := o.sep()
+
+	for _, f := range r.fields(t, o.tagName) {
+		if o.excludesType(f.typ) {
+			continue
+		}
+
+		var (
+			fieldPath       = childPath(path, quoteComponent(f.name, sep), sep)
+			fieldComponents = append(components[:len(components):len(components)], f.name)
+			depth           = len(fieldComponents)
+			terminal        = parsable(f.typ)
+		)
+
+		if o.maxDepth > 0 && (depth > o.maxDepth || !terminal && depth == o.maxDepth) {
+			continue
+		}
+		if !allowed(fieldComponents, terminal, includes, excludes) {
+			continue
+		}
+
+		if terminal {
+			out = append(out, setting{
+				path:       fieldPath,
+				components: fieldComponents,
+				field:      t.Field(f.index),
+			})
+			continue
+		}
+
+		ft := f.typ
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			out = r.appendSettings(out, ft, fieldPath, fieldComponents, o, includes, excludes, seen)
+		}
+	}
+
+	return out
+}
+
+// parsable returns true if values of type t can be parsed from a string by
+// parseValue.
+func parsable(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return true
+		}
+		elem := t.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		return elem.Kind() != reflect.Slice && parsable(elem)
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseValue parses s into a value of type t.  Types implementing
+// encoding.TextUnmarshaler (e.g. time.Time, net.IP) parse themselves,
+// time.Duration accepts anything time.ParseDuration does, integers accept
+// the prefixes understood by strconv.ParseInt (e.g. "0x"), and slices are
+// split on the delimiter, with surrounding whitespace trimmed from each
+// element.  An empty string yields an empty slice.
+func parseValue(t reflect.Type, s string, delim string) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		elem, err := parseValue(t.Elem(), s, delim)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	v := reflect.New(t).Elem()
+
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+		return v, nil
+	}
+
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetFloat(f)
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			return v, nil
+		}
+		v.Set(reflect.MakeSlice(t, 0, 0))
+		if s == "" {
+			return v, nil
+		}
+		for _, part := range strings.Split(s, delim) {
+			elem, err := parseValue(t.Elem(), strings.TrimSpace(part), delim)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Set(reflect.Append(v, elem))
+		}
+
+	default:
+		return reflect.Value{}, ErrTypeMismatch
+	}
+
+	return v, nil
+}
+
+// formatValue formats v, which must be of a type accepted by parseValue
+// (other than a slice parsed element by element), such that parseValue
+// restores it.
+func formatValue(v reflect.Value) string {
+	if v.Type().Implements(textMarshalerType) {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return string(v.Bytes())
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,53 @@
+package metaflector
+
+import (
+	"reflect"
+)
+
+// StaticFields is implemented by types with a generated, reflection-free
+// implementation of TerminalFields, as written by the metaflector-gen command:
+//
+//	//go:generate metaflector-gen -type Config
+//
+// TerminalFields uses it in place of reflection when no options prune the
+// traversal.
+type StaticFields interface {
+	// MetaflectorTerminalFields returns the terminal fields of the value in
+	// declaration order, or false if the code was generated for a different
+	// separator or tag name.
+	MetaflectorTerminalFields(sep string, tagName string) (paths []string, ok bool)
+}
+
+// StaticGetter is implemented by types with a generated, reflection-free
+// implementation of Get, as written by the metaflector-gen command.  Get uses
+// it in place of reflection for the paths it handles.
+type StaticGetter interface {
+	// MetaflectorGet returns the value at path, or false if path isn't one of
+	// the terminal fields the code was generated for (with the given
+	// separator and tag name).
+	MetaflectorGet(path string, sep string, tagName string) (value interface{}, ok bool)
+}
+
+// StaticSetter is implemented by pointers to types with a generated,
+// reflection-free implementation of Set, as written by the metaflector-gen
+// command.  Set uses it in place of reflection for the paths it handles.
+type StaticSetter interface {
+	// MetaflectorSet assigns value to the field at path, returning false
+	// without modifying anything if path isn't one of the terminal fields the
+	// code was generated for (with the given separator and tag name), or if
+	// value isn't of the field's exact type.
+	MetaflectorSet(path string, value interface{}, sep string, tagName string) (ok bool)
+}
+
+// static returns true if the generated code for obj may be used in place of
+// reflection under the options, i.e. none of them prune the traversal and obj
+// isn't a nil pointer (which would panic when calling value methods).
+func (o *options) static(obj interface{}) bool {
+	if o.maxDepth > 0 || len(o.patterns) > 0 || len(o.excludeTypes) > 0 {
+		return false
+	}
+	if v := reflect.ValueOf(obj); v.Kind() == reflect.Ptr && v.IsNil() {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,356 @@
+// Code generated by "metaflector-gen -type StaticOrder,StaticDoc,StaticOuter -output static_gen_test.go"; DO NOT EDIT.
+
+package metaflector
+
+// MetaflectorTerminalFields implements metaflector.StaticFields.
+func (x StaticOrder) MetaflectorTerminalFields(sep string, tagName string) ([]string, bool) {
+	if sep != "." || tagName != "" {
+		return nil, false
+	}
+	return metaflectorFieldsStaticOrder(&x, "", []string{}), true
+}
+
+// MetaflectorGet implements metaflector.StaticGetter.
+func (x StaticOrder) MetaflectorGet(path string, sep string, tagName string) (interface{}, bool) {
+	if sep != "." || tagName != "" {
+		return nil, false
+	}
+	switch path {
+	case "ID":
+		return x.ID, true
+	case "Priority":
+		return uint64(x.Priority), true
+	case "Total":
+		return float64(x.Total), true
+	case "Customer.Name":
+		if x.Customer == nil {
+			return nil, true
+		} else {
+			return (*x.Customer).Name, true
+		}
+	case "Customer.Email":
+		if x.Customer == nil {
+			return nil, true
+		} else {
+			return (*x.Customer).Email, true
+		}
+	case "Customer.Address.City":
+		if x.Customer == nil {
+			return nil, true
+		} else {
+			return (*x.Customer).Address.City, true
+		}
+	case "Customer.Address.Zip":
+		if x.Customer == nil {
+			return nil, true
+		} else {
+			return int64((*x.Customer).Address.Zip), true
+		}
+	case "Lines.SKU":
+		{
+			out1 := []interface{}{}
+			for _, e1 := range x.Lines {
+				out1 = append(out1, e1.SKU)
+			}
+			return out1, true
+		}
+	case "Lines.Qty":
+		{
+			out2 := []interface{}{}
+			for _, e2 := range x.Lines {
+				out2 = append(out2, int64(e2.Qty))
+			}
+			return out2, true
+		}
+	case "Lines.Labels":
+		{
+			out3 := []interface{}{}
+			for _, e3 := range x.Lines {
+				{
+					out4 := make([]interface{}, 0, len(e3.Labels))
+					for _, e4 := range e3.Labels {
+						out4 = append(out4, e4)
+					}
+					out3 = append(out3, out4)
+				}
+			}
+			return out3, true
+		}
+	case "Lines.Parts.Serial":
+		{
+			out5 := []interface{}{}
+			for _, e5 := range x.Lines {
+				{
+					out6 := []interface{}{}
+					for _, e6 := range e5.Parts {
+						if e6 != nil {
+							out6 = append(out6, (*e6).Serial)
+						} else {
+							out6 = append(out6, nil)
+						}
+					}
+					out5 = append(out5, out6)
+				}
+			}
+			return out5, true
+		}
+	case "Notes.Text":
+		if x.Notes == nil {
+			return nil, true
+		} else {
+			{
+				out7 := []interface{}{}
+				for _, e7 := range *x.Notes {
+					if e7 != nil && *e7 != nil {
+						out7 = append(out7, (**e7).Text)
+					} else {
+						out7 = append(out7, nil)
+					}
+				}
+				return out7, true
+			}
+		}
+	case "Tags":
+		{
+			out8 := make([]interface{}, 0, len(x.Tags))
+			for _, e8 := range x.Tags {
+				out8 = append(out8, e8)
+			}
+			return out8, true
+		}
+	case "Counts":
+		return x.Counts, true
+	}
+	return nil, false
+}
+
+// MetaflectorSet implements metaflector.StaticSetter.
+func (x *StaticOrder) MetaflectorSet(path string, value interface{}, sep string, tagName string) bool {
+	if sep != "." || tagName != "" {
+		return false
+	}
+	switch path {
+	case "ID":
+		v, ok := value.(int64)
+		if !ok {
+			return false
+		}
+		x.ID = v
+		return true
+	case "Priority":
+		v, ok := value.(StaticLevel)
+		if !ok {
+			return false
+		}
+		x.Priority = v
+		return true
+	case "Total":
+		v, ok := value.(float32)
+		if !ok {
+			return false
+		}
+		x.Total = v
+		return true
+	case "Customer.Name":
+		v, ok := value.(string)
+		if !ok {
+			return false
+		}
+		if x.Customer == nil {
+			x.Customer = new(StaticCustomer)
+		}
+		(*x.Customer).Name = v
+		return true
+	case "Customer.Email":
+		v, ok := value.(*string)
+		if !ok {
+			return false
+		}
+		if x.Customer == nil {
+			x.Customer = new(StaticCustomer)
+		}
+		(*x.Customer).Email = v
+		return true
+	case "Customer.Address.City":
+		v, ok := value.(string)
+		if !ok {
+			return false
+		}
+		if x.Customer == nil {
+			x.Customer = new(StaticCustomer)
+		}
+		(*x.Customer).Address.City = v
+		return true
+	case "Customer.Address.Zip":
+		v, ok := value.(int)
+		if !ok {
+			return false
+		}
+		if x.Customer == nil {
+			x.Customer = new(StaticCustomer)
+		}
+		(*x.Customer).Address.Zip = v
+		return true
+	case "Tags":
+		v, ok := value.([]string)
+		if !ok {
+			return false
+		}
+		x.Tags = v
+		return true
+	case "Counts":
+		v, ok := value.(map[string]int)
+		if !ok {
+			return false
+		}
+		x.Counts = v
+		return true
+	}
+	return false
+}
+
+// MetaflectorGet implements metaflector.StaticGetter.
+func (x StaticDoc) MetaflectorGet(path string, sep string, tagName string) (interface{}, bool) {
+	if sep != "." || tagName != "" {
+		return nil, false
+	}
+	switch path {
+	case "Title":
+		return x.Title, true
+	}
+	return nil, false
+}
+
+// MetaflectorSet implements metaflector.StaticSetter.
+func (x *StaticDoc) MetaflectorSet(path string, value interface{}, sep string, tagName string) bool {
+	if sep != "." || tagName != "" {
+		return false
+	}
+	switch path {
+	case "Title":
+		v, ok := value.(string)
+		if !ok {
+			return false
+		}
+		x.Title = v
+		return true
+	}
+	return false
+}
+
+// MetaflectorGet implements metaflector.StaticGetter.
+func (x StaticOuter) MetaflectorGet(path string, sep string, tagName string) (interface{}, bool) {
+	if sep != "." || tagName != "" {
+		return nil, false
+	}
+	switch path {
+	case "Name":
+		return x.Name, true
+	}
+	return nil, false
+}
+
+// MetaflectorSet implements metaflector.StaticSetter.
+func (x *StaticOuter) MetaflectorSet(path string, value interface{}, sep string, tagName string) bool {
+	if sep != "." || tagName != "" {
+		return false
+	}
+	switch path {
+	case "Name":
+		v, ok := value.(string)
+		if !ok {
+			return false
+		}
+		x.Name = v
+		return true
+	}
+	return false
+}
+
+func metaflectorFieldsStaticOrder(x *StaticOrder, prefix string, out []string) []string {
+	pre := prefix
+	if prefix != "" {
+		pre += "."
+	}
+	out = append(out, pre+"ID")
+	out = append(out, pre+"Priority")
+	out = append(out, pre+"Total")
+	if x.Customer != nil {
+		out = metaflectorFieldsStaticOrder_StaticCustomer(x.Customer, pre+"Customer", out)
+	}
+	if len(x.Lines) > 0 {
+		out = metaflectorFieldsStaticOrder_StaticLine(&x.Lines[0], pre+"Lines", out)
+	}
+	if x.Notes != nil {
+		for _, e := range *x.Notes {
+			if e != nil {
+				if *e != nil {
+					out = metaflectorFieldsStaticOrder_StaticNote(*e, pre+"Notes", out)
+				}
+				break
+			}
+		}
+	}
+	out = append(out, pre+"Tags")
+	out = append(out, pre+"Counts")
+	if x.Parent != nil {
+		out = metaflectorFieldsStaticOrder(x.Parent, pre+"Parent", out)
+	}
+	return out
+}
+
+func metaflectorFieldsStaticOrder_StaticCustomer(x *StaticCustomer, prefix string, out []string) []string {
+	pre := prefix
+	if prefix != "" {
+		pre += "."
+	}
+	out = append(out, pre+"Name")
+	out = append(out, pre+"Email")
+	out = metaflectorFieldsStaticOrder_StaticAddress(&x.Address, pre+"Address", out)
+	return out
+}
+
+func metaflectorFieldsStaticOrder_StaticLine(x *StaticLine, prefix string, out []string) []string {
+	pre := prefix
+	if prefix != "" {
+		pre += "."
+	}
+	out = append(out, pre+"SKU")
+	out = append(out, pre+"Qty")
+	out = append(out, pre+"Labels")
+	for _, e := range x.Parts {
+		if e != nil {
+			out = metaflectorFieldsStaticOrder_StaticPart(e, pre+"Parts", out)
+			break
+		}
+	}
+	return out
+}
+
+func metaflectorFieldsStaticOrder_StaticNote(x *StaticNote, prefix string, out []string) []string {
+	pre := prefix
+	if prefix != "" {
+		pre += "."
+	}
+	out = append(out, pre+"Text")
+	return out
+}
+
+func metaflectorFieldsStaticOrder_StaticAddress(x *StaticAddress, prefix string, out []string) []string {
+	pre := prefix
+	if prefix != "" {
+		pre += "."
+	}
+	out = append(out, pre+"City")
+	out = append(out, pre+"Zip")
+	return out
+}
+
+func metaflectorFieldsStaticOrder_StaticPart(x *StaticPart, prefix string, out []string) []string {
+	pre := prefix
+	if prefix != "" {
+		pre += "."
+	}
+	out = append(out, pre+"Serial")
+	return out
+}
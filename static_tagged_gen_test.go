@@ -0,0 +1,114 @@
+// Code generated by "metaflector-gen -type StaticTagged -sep / -tag json -output static_tagged_gen_test.go"; DO NOT EDIT.
+
+package metaflector
+
+// MetaflectorTerminalFields implements metaflector.StaticFields.
+func (x StaticTagged) MetaflectorTerminalFields(sep string, tagName string) ([]string, bool) {
+	if sep != "/" || tagName != "json" {
+		return nil, false
+	}
+	return metaflectorFieldsStaticTagged(&x, "", []string{}), true
+}
+
+// MetaflectorGet implements metaflector.StaticGetter.
+func (x StaticTagged) MetaflectorGet(path string, sep string, tagName string) (interface{}, bool) {
+	if sep != "/" || tagName != "json" {
+		return nil, false
+	}
+	switch path {
+	case "name":
+		return x.Name, true
+	case "a.b":
+		return x.Dotted, true
+	case "[\"a/b\"]":
+		return x.Slash, true
+	case "inner/City":
+		if x.Inner == nil {
+			return nil, true
+		} else {
+			return (*x.Inner).City, true
+		}
+	case "inner/Zip":
+		if x.Inner == nil {
+			return nil, true
+		} else {
+			return int64((*x.Inner).Zip), true
+		}
+	}
+	return nil, false
+}
+
+// MetaflectorSet implements metaflector.StaticSetter.
+func (x *StaticTagged) MetaflectorSet(path string, value interface{}, sep string, tagName string) bool {
+	if sep != "/" || tagName != "json" {
+		return false
+	}
+	switch path {
+	case "name":
+		v, ok := value.(string)
+		if !ok {
+			return false
+		}
+		x.Name = v
+		return true
+	case "a.b":
+		v, ok := value.(string)
+		if !ok {
+			return false
+		}
+		x.Dotted = v
+		return true
+	case "[\"a/b\"]":
+		v, ok := value.(string)
+		if !ok {
+			return false
+		}
+		x.Slash = v
+		return true
+	case "inner/City":
+		v, ok := value.(string)
+		if !ok {
+			return false
+		}
+		if x.Inner == nil {
+			x.Inner = new(StaticAddress)
+		}
+		(*x.Inner).City = v
+		return true
+	case "inner/Zip":
+		v, ok := value.(int)
+		if !ok {
+			return false
+		}
+		if x.Inner == nil {
+			x.Inner = new(StaticAddress)
+		}
+		(*x.Inner).Zip = v
+		return true
+	}
+	return false
+}
+
+func metaflectorFieldsStaticTagged(x *StaticTagged, prefix string, out []string) []string {
+	pre := prefix
+	if prefix != "" {
+		pre += "/"
+	}
+	out = append(out, pre+"name")
+	out = append(out, pre+"a.b")
+	out = append(out, prefix+"[\"a/b\"]")
+	if x.Inner != nil {
+		out = metaflectorFieldsStaticTagged_StaticAddress(x.Inner, pre+"inner", out)
+	}
+	return out
+}
+
+func metaflectorFieldsStaticTagged_StaticAddress(x *StaticAddress, prefix string, out []string) []string {
+	pre := prefix
+	if prefix != "" {
+		pre += "/"
+	}
+	out = append(out, pre+"City")
+	out = append(out, pre+"Zip")
+	return out
+}
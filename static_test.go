@@ -0,0 +1,299 @@
+package metaflector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//go:generate go run ./cmd/metaflector-gen -type StaticOrder,StaticDoc,StaticOuter -output static_gen_test.go
+//go:generate go run ./cmd/metaflector-gen -type StaticTagged -sep / -tag json -output static_tagged_gen_test.go
+
+type StaticOrder struct {
+	ID       int64
+	Priority StaticLevel
+	Total    float32
+	Customer *StaticCustomer
+	Lines    []StaticLine
+	Notes    *[]**StaticNote
+	Tags     []string
+	Counts   map[string]int
+	Parent   *StaticOrder
+	Callback func()
+	Any      interface{}
+	internal int
+}
+
+type StaticLevel uint8
+
+type StaticCustomer struct {
+	Name    string
+	Email   *string
+	Address StaticAddress
+}
+
+type StaticAddress struct {
+	City string
+	Zip  int
+}
+
+type StaticLine struct {
+	SKU    string
+	Qty    int
+	Labels []string
+	Parts  []*StaticPart
+}
+
+type StaticPart struct {
+	Serial string
+}
+
+type StaticNote struct {
+	Text string
+}
+
+// StaticDoc's terminal fields depend on its contents.
+type StaticDoc struct {
+	Title string
+	Meta  map[string]interface{}
+}
+
+// StaticOuter holds a type supplying its own fields, which generated code
+// leaves to reflection.
+type StaticOuter struct {
+	Name string
+	P    StaticProxy
+}
+
+type StaticProxy struct {
+	Real string
+}
+
+func (p *StaticProxy) MetaflectorFields() []string {
+	return []string{"Virtual"}
+}
+
+func (p *StaticProxy) MetaflectorField(name string) (interface{}, bool) {
+	if name == "Virtual" {
+		return "v:" + p.Real, true
+	}
+	return nil, false
+}
+
+type StaticTagged struct {
+	Name   string         `json:"name"`
+	Dotted string         `json:"a.b"`
+	Slash  string         `json:"a/b"`
+	Inner  *StaticAddress `json:"inner"`
+	Hidden string         `json:"-"`
+}
+
+func staticOrders() []StaticOrder {
+	var (
+		email = "ann@example.com"
+		note  = &StaticNote{Text: "fragile"}
+		notes = []**StaticNote{nil, &note}
+	)
+	return []StaticOrder{
+		{},
+		{
+			ID:       7,
+			Priority: 2,
+			Total:    9.5,
+			Customer: &StaticCustomer{Name: "Ann", Email: &email, Address: StaticAddress{City: "Oslo", Zip: 150}},
+			Lines: []StaticLine{
+				{SKU: "a", Qty: 1, Labels: []string{"x"}, Parts: []*StaticPart{nil, {Serial: "s1"}}},
+				{SKU: "b", Qty: 2},
+			},
+			Notes:  &notes,
+			Tags:   []string{"rush"},
+			Counts: map[string]int{"a": 1},
+			Parent: &StaticOrder{ID: 6, Customer: &StaticCustomer{Name: "Bob"}},
+		},
+		{
+			Lines: []StaticLine{{SKU: "c"}},
+			Notes: &[]**StaticNote{new(*StaticNote)},
+		},
+	}
+}
+
+func TestStaticDetection(t *testing.T) {
+	var (
+		_ StaticFields = StaticOrder{}
+		_ StaticGetter = StaticOrder{}
+		_ StaticSetter = &StaticOrder{}
+		_ StaticGetter = StaticDoc{}
+		_ StaticSetter = &StaticDoc{}
+	)
+	if _, ok := interface{}(StaticDoc{}).(StaticFields); ok {
+		t.Errorf("Expected no generated TerminalFields for a type with generic fields")
+	}
+
+	order := staticOrders()[1]
+	if _, ok := order.MetaflectorTerminalFields("/", ""); ok {
+		t.Errorf("Expected generated TerminalFields to reject a different separator")
+	}
+	if _, ok := order.MetaflectorGet("ID", ".", "json"); ok {
+		t.Errorf("Expected generated Get to reject a different tag name")
+	}
+	if _, ok := order.MetaflectorGet("Lines[0].SKU", ".", ""); ok {
+		t.Errorf("Expected generated Get to leave indexes to reflection")
+	}
+	if expected, actual := "a", Get(order, "Lines[0].SKU"); actual != expected {
+		t.Errorf("Expected value=%v but actual=%v", expected, actual)
+	}
+	if ok := (&order).MetaflectorSet("ID", 8, ".", ""); ok {
+		t.Errorf("Expected generated Set to leave conversions to reflection")
+	}
+	if err := Set(&order, "ID", 8); err != nil || order.ID != 8 {
+		t.Errorf("Expected ID=8 but actual=%v (err=%v)", order.ID, err)
+	}
+
+	// Options which prune the traversal aren't handled by generated code.
+	if expected, actual := []string{"Counts", "ID", "Priority", "Tags", "Total"}, TerminalFields(order, WithMaxDepth(1)); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%v but actual=%v", expected, actual)
+	}
+	if actual := TerminalFields((*StaticOrder)(nil)); len(actual) != 0 {
+		t.Errorf("Expected no fields for a nil pointer but actual=%v", actual)
+	}
+}
+
+func TestStaticTerminalFields(t *testing.T) {
+	r := New()
+	objs := []interface{}{StaticDoc{Meta: map[string]interface{}{"a": 1}}}
+	for _, order := range staticOrders() {
+		objs = append(objs, order, &order)
+	}
+
+	for i, obj := range objs {
+		for _, opts := range [][]Option{nil, {WithDeclarationOrder()}} {
+			var (
+				expected = r.terminalFields(obj, r.options(opts))
+				actual   = r.TerminalFields(obj, opts...)
+			)
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("[i=%v] Expected fields=%v but actual=%v", i, expected, actual)
+			}
+		}
+	}
+
+	tagged := StaticTagged{Inner: &StaticAddress{}}
+	paths, ok := tagged.MetaflectorTerminalFields("/", "json")
+	if expected := []string{"name", "a.b", `["a/b"]`, "inner/City", "inner/Zip"}; !ok || !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected fields=%v but actual=%v (ok=%v)", expected, paths, ok)
+	}
+	r = New(WithSeparator("/"), WithTagName("json"))
+	if expected, actual := r.terminalFields(tagged, r.options(nil)), r.TerminalFields(tagged); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%v but actual=%v", expected, actual)
+	}
+}
+
+func TestStaticGet(t *testing.T) {
+	r := New()
+	o := r.options(nil)
+	for i, order := range staticOrders() {
+		for _, path := range r.terminalFields(staticOrders()[1], o) {
+			components, _ := splitPath(path, ".")
+			expected, _ := r.get(reflect.ValueOf(order), components, o)
+			actual, ok := order.MetaflectorGet(path, ".", "")
+			if !ok {
+				// Recursive types are left to reflection.
+				if strings.HasPrefix(path, "Parent.") {
+					continue
+				}
+				t.Errorf("[i=%v] Expected generated Get to handle path=%v", i, path)
+				continue
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("[i=%v path=%v] Expected value=%#v but actual=%#v", i, path, expected, actual)
+			}
+			if actual := r.Get(order, path); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("[i=%v path=%v] Expected value=%#v but actual=%#v", i, path, expected, actual)
+			}
+		}
+	}
+
+	tagged := StaticTagged{Dotted: "x", Slash: "y"}
+	for path, expected := range map[string]interface{}{"a.b": "x", `["a/b"]`: "y", "inner/Zip": nil} {
+		if actual, ok := tagged.MetaflectorGet(path, "/", "json"); !ok || actual != expected {
+			t.Errorf("[path=%v] Expected value=%v but actual=%v (ok=%v)", path, expected, actual, ok)
+		}
+	}
+}
+
+func TestStaticCustom(t *testing.T) {
+	if _, ok := interface{}(StaticOuter{}).(StaticFields); ok {
+		t.Errorf("Expected no generated TerminalFields for a type holding a FieldLister")
+	}
+
+	var (
+		r     = New()
+		o     = r.options(nil)
+		outer = StaticOuter{Name: "n", P: StaticProxy{Real: "r"}}
+	)
+	for i, obj := range []interface{}{outer, &outer} {
+		if expected, actual := r.terminalFields(obj, o), r.TerminalFields(obj); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected fields=%v but actual=%v", i, expected, actual)
+		}
+		for _, path := range []string{"Name", "P.Virtual", "P.Real"} {
+			components, _ := splitPath(path, ".")
+			if expected, _ := r.get(reflect.ValueOf(obj), components, o); !reflect.DeepEqual(r.Get(obj, path), expected) {
+				t.Errorf("[i=%v path=%v] Expected value=%#v but actual=%#v", i, path, expected, r.Get(obj, path))
+			}
+		}
+	}
+	if _, ok := outer.MetaflectorGet("P.Real", ".", ""); ok {
+		t.Errorf("Expected generated Get to leave FieldGetter implementations to reflection")
+	}
+}
+
+func TestStaticSet(t *testing.T) {
+	var (
+		r      = New()
+		o      = r.options(nil)
+		email  = "bob@example.com"
+		values = map[string]interface{}{
+			"ID":                    int64(1),
+			"Priority":              StaticLevel(3),
+			"Total":                 float32(1.5),
+			"Customer.Name":         "Bob",
+			"Customer.Email":        &email,
+			"Customer.Address.City": "Rome",
+			"Customer.Address.Zip":  100,
+			"Tags":                  []string{"t"},
+			"Counts":                map[string]int{"b": 2},
+		}
+	)
+
+	for i := range staticOrders() {
+		for path, value := range values {
+			var (
+				expected      = staticOrders()[i]
+				actual        = staticOrders()[i]
+				components, _ = splitPath(path, ".")
+			)
+			if err := r.set(reflect.ValueOf(&expected).Elem(), components, value, o); err != nil {
+				t.Fatalf("[i=%v path=%v] Unexpected error: %s", i, path, err)
+			}
+			if ok := (&actual).MetaflectorSet(path, value, ".", ""); !ok {
+				t.Errorf("[i=%v] Expected generated Set to handle path=%v", i, path)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("[i=%v path=%v] Expected value=%+v but actual=%+v", i, path, expected, actual)
+			}
+		}
+	}
+
+	var order StaticOrder
+	if ok := (&order).MetaflectorSet("Lines.SKU", "x", ".", ""); ok {
+		t.Errorf("Expected generated Set to leave paths through slices to reflection")
+	}
+	if ok := (&order).MetaflectorSet("Customer.Name", 1, ".", ""); ok || order.Customer != nil {
+		t.Errorf("Expected generated Set to reject a mismatched type without modifying anything")
+	}
+
+	var tagged StaticTagged
+	if ok := (&tagged).MetaflectorSet("inner/City", "Rome", "/", "json"); !ok || tagged.Inner == nil || tagged.Inner.City != "Rome" {
+		t.Errorf("Expected inner/City=Rome but actual=%+v (ok=%v)", tagged.Inner, ok)
+	}
+}
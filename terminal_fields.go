@@ -3,50 +3,106 @@ package metaflector
 import (
 	"reflect"
 	"sort"
-	"strings"
 )
 
-// Separator is the string to use as the delimiter between field names.
+// Separator is the string to use as the delimiter between field names by the
+// package-level functions.  Prefer New(WithSeparator(...)) when a different
+// separator is needed, as modifying this value is not goroutine-safe.
 var Separator = "."
 
 // TerminalFields returns a slice of strings representing the full path in dot
 // notation for each "terminal" field, where a terminal field is defined as a
 // primitive type (and without additional sub-fields, e.g. an int).
 //
+// Options such as WithMaxDepth, WithFilter and WithExcludedTypes prune the
+// traversal so that unwanted subtrees are never visited.
+//
 // This implementation uses a BFS queue-based traversal to minimize stack
-// depth.
+// depth, unless obj implements StaticFields (see the metaflector-gen command).
 //
 // Important note: Circular references aren't supported yet and will blow up.
-func TerminalFields(obj interface{}) []string {
+func TerminalFields(obj interface{}, opts ...Option) []string {
+	return defaultReflector.TerminalFields(obj, opts...)
+}
+
+// TerminalFields is the Reflector equivalent of the package-level
+// TerminalFields.
+func (r *Reflector) TerminalFields(obj interface{}, opts ...Option) []string {
 	if obj == nil {
 		return nil
 	}
 
+	o := r.options(opts)
+	if s, ok := obj.(StaticFields); ok && o.static(obj) {
+		if paths, ok := s.MetaflectorTerminalFields(o.sep(), o.tagName); ok {
+			if !o.declOrder {
+				sort.Strings(paths)
+			}
+			return paths
+		}
+	}
+
+	return r.terminalFields(obj, o)
+}
+
+// terminalFields implements TerminalFields using reflection.
+func (r *Reflector) terminalFields(obj interface{}, o *options) []string {
 	type item struct {
-		obj  interface{}
-		path string
+		obj        interface{}
+		path       string
+		components []string
+		order      []int
 	}
 
 	var (
-		paths = []string{}
-		queue = []item{
+		sep                = o.sep()
+		includes, excludes = compilePatterns(o.patterns, sep)
+		paths              = &orderedPaths{paths: []string{}}
+		queue              = []item{
 			{obj: obj},
 		}
 	)
 
 	for len(queue) > 0 {
-		EachField(queue[0].obj, func(child interface{}, name string, kind reflect.Kind) {
-			if len(queue[0].path) > 0 {
-				name = queue[0].path + Separator + name
+		n := 0
+		r.eachField(queue[0].obj, o, func(child interface{}, name string, kind reflect.Kind) {
+			// Position of the field amongst its siblings, used to reconstruct
+			// declaration order after the BFS.
+			order := make([]int, len(queue[0].order)+1)
+			copy(order, queue[0].order)
+			order[len(order)-1] = n
+			n++
+
+			parts, _ := splitPath(name, sep)
+			components := make([]string, 0, len(queue[0].components)+len(parts))
+			components = append(append(components, queue[0].components...), parts...)
+			if len(queue[0].components) > 0 {
+				name = appendPath(queue[0].path, name, sep)
+			}
+
+			var (
+				depth    = len(components)
+				terminal = isTerminal(child, kind)
+			)
+
+			// Prune anything beyond the depth limit or outside of the filter.
+			if o.maxDepth > 0 && (depth > o.maxDepth || !terminal && depth == o.maxDepth) {
+				return
+			}
+			if !allowed(components, terminal, includes, excludes) {
+				return
 			}
 
 			// Filter and exclude non-terminal types.
-			if isTerminal(kind) {
-				paths = append(paths, name)
+			if terminal {
+				paths.paths = append(paths.paths, name)
+				paths.orders = append(paths.orders, order)
 			} else {
 				i := item{
-					obj:  child,
-					path: name,
+					obj:        child,
+					path:       name,
+					components: components,
+					order:      order,
 				}
 				queue = append(queue, i)
 			}
@@ -54,9 +110,37 @@ func TerminalFields(obj interface{}) []string {
 		queue = queue[1:]
 	}
 
-	sort.Strings(paths)
+	if o.declOrder {
+		sort.Sort(paths)
+	} else {
+		sort.Strings(paths.paths)
+	}
+
+	return paths.paths
+}
+
+// orderedPaths sorts paths by the declaration position of each of their
+// components, yielding a depth-first declaration ordering.
+type orderedPaths struct {
+	paths  []string
+	orders [][]int
+}
+
+func (op *orderedPaths) Len() int { return len(op.paths) }
 
-	return paths
+func (op *orderedPaths) Swap(i, j int) {
+	op.paths[i], op.paths[j] = op.paths[j], op.paths[i]
+	op.orders[i], op.orders[j] = op.orders[j], op.orders[i]
+}
+
+func (op *orderedPaths) Less(i, j int) bool {
+	a, b := op.orders[i], op.orders[j]
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
 }
 
 // IterFunc is the type signature of callbacks sent to `EachField`.
@@ -66,49 +150,108 @@ type IterFunc func(child interface{}, name string, kind reflect.Kind)
 // a struct.  The function returns false if the passed object cannot be
 // resolved to a struct or non-empty slice / array (i.e. if must be a
 // non-terminal type).
+//
+// Generic trees decoded from JSON or YAML into an interface{} are supported
+// too: the keys of a map[string]interface{} are reported as fields, sorted by
+// name, with nested objects reported as reflect.Map and nulls as
+// reflect.Interface.  Unlike slices of structs, which are described by their
+// first element, the fields of a []interface{} are the union of those of all
+// of the objects in it, each reported with a []interface{} of its values
+// across them (as Get would return).  Slices whose elements have no fields of
+// their own, e.g. []string, are reported as terminal fields.
+//
+// Types implementing FieldLister (and FieldGetter) report the fields they
+// supply rather than those found by reflection.
 func EachField(obj interface{}, fn IterFunc) (ok bool) {
+	return defaultReflector.EachField(obj, fn)
+}
+
+// EachField is the Reflector equivalent of the package-level EachField.
+func (r *Reflector) EachField(obj interface{}, fn IterFunc) (ok bool) {
+	return r.eachField(obj, r.options(nil), fn)
+}
+
+// eachField implements EachField, skipping any fields excluded by the options.
+func (r *Reflector) eachField(obj interface{}, o *options, fn IterFunc) (ok bool) {
+	if v := reflect.ValueOf(obj); v.IsValid() {
+		if lister, getter := customFields(v); lister != nil {
+			r.eachCustomField(v, lister, getter, o, fn)
+			return true
+		}
+	}
+
+	if list := indirect(reflect.ValueOf(obj)); isGenericList(list) && hasObjects(list) {
+		r.eachGenericField(list, "", o, fn)
+		return true
+	}
+
 	if obj, ok = ResolveUnderlying(obj); !ok || obj == nil {
 		ok = false
 		return
 	}
 
 	v := reflect.ValueOf(obj)
+	if isObject(v) {
+		r.eachGenericField(v, "", o, fn)
+		return
+	}
 
-	for i := 0; i < v.NumField(); i++ {
-		// Skip unexported (signaled by non-mepty pkgpath) or anonymous fields.
-		if sf := v.Type().Field(i); sf.PkgPath != "" || sf.Anonymous {
+	for _, f := range r.fields(v.Type(), o.tagName) {
+		if o.excludesType(f.typ) {
 			continue
 		}
+		r.eachFieldValue(v.Field(f.index), quoteComponent(f.name, o.sep()), o, fn)
+	}
 
-		var (
-			field = v.Field(i)
-			name  = v.Type().Field(i).Name
-			kind  = field.Kind()
-		)
+	ok = true
+	return
+}
 
-		for kind == reflect.Ptr {
-			// Resolve underlying pointer type.
-			kind = field.Type().Elem().Kind()
-		}
+// eachFieldValue invokes fn for a field with the given value, or for each of
+// its nested fields when it's a slice or array of structs.
+func (r *Reflector) eachFieldValue(field reflect.Value, name string, o *options, fn IterFunc) {
+	kind := field.Kind()
+	if isCustom(field.Interface()) {
+		// Descended into by way of its FieldLister.
+		fn(field.Interface(), name, kind)
+		return
+	}
 
-		switch kind {
-		case reflect.Struct:
-			fn(field.Interface(), name, kind)
+	for kind == reflect.Ptr {
+		// Resolve underlying pointer type.
+		kind = field.Type().Elem().Kind()
+	}
 
-		case reflect.Slice, reflect.Array:
-			if firstObj, ok := ResolveUnderlying(field.Interface()); ok {
-				EachField(firstObj, func(child interface{}, childName string, childKind reflect.Kind) {
-					fn(child, name+Separator+childName, childKind)
-				})
-			}
+	switch kind {
+	case reflect.Struct:
+		fn(field.Interface(), name, kind)
 
-		case reflect.String, reflect.Float32, reflect.Float64, reflect.Bool, reflect.Map, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	case reflect.Slice, reflect.Array:
+		elemType := indirectType(field.Type()).Elem()
+		if elemType.Kind() == reflect.Interface {
+			if list := indirect(field); isGenericList(list) {
+				r.genericField(list, name, o, fn)
+				return
+			}
+		} else if !hasFields(elemType) {
 			fn(unreflect(field), name, kind)
+			return
+		}
+		firstObj, ok := ResolveUnderlying(field.Interface())
+		if list := indirect(field); list.IsValid() {
+			if custom, found := firstCustom(list); found {
+				firstObj, ok = custom, true
+			}
+		}
+		if ok {
+			r.eachField(firstObj, o, func(child interface{}, childName string, childKind reflect.Kind) {
+				fn(child, appendPath(name, childName, o.sep()), childKind)
+			})
 		}
-	}
 
-	ok = true
-	return
+	case reflect.String, reflect.Float32, reflect.Float64, reflect.Bool, reflect.Map, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fn(unreflect(field), name, kind)
+	}
 }
 
 // ResolveUnderlying takes an interface{} (object) and resolves it to an
@@ -119,7 +262,8 @@ func EachField(obj interface{}, fn IterFunc) (ok bool) {
 // 2. Slices and arrays, when not empty, are resolved to the type of the first
 // element.
 //
-// 3. Test if the end result is a struct.
+// 3. Test if the end result is a struct, or a generic object decoded from
+// JSON or YAML (i.e. a map[string]interface{}).
 func ResolveUnderlying(obj interface{}) (resolved interface{}, ok bool) {
 	if obj, ok = resolvePointer(obj); !ok {
 		return
@@ -133,7 +277,7 @@ func ResolveUnderlying(obj interface{}) (resolved interface{}, ok bool) {
 		obj = nil
 		// Find first non-nil element.
 		for i := 0; i < v.Len(); i++ {
-			if value := v.Index(i); isStruct(value.Interface()) || !value.IsNil() {
+			if value := v.Index(i); !isNil(value) {
 				obj = value.Interface()
 				break
 			}
@@ -144,7 +288,7 @@ func ResolveUnderlying(obj interface{}) (resolved interface{}, ok bool) {
 		return
 	}
 
-	if !isStruct(obj) {
+	if !isStruct(obj) && !isObject(reflect.ValueOf(obj)) {
 		ok = false
 		return
 	}
@@ -171,6 +315,15 @@ func resolvePointer(obj interface{}) (interface{}, bool) {
 	return obj, true
 }
 
+// isNil returns true if v is a nil pointer, interface, map or slice.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
 func isPointer(obj interface{}) bool {
 	if obj == nil {
 		return false
@@ -198,100 +351,156 @@ func hasType(obj interface{}, types []reflect.Kind) bool {
 	return false
 }
 
-// isTerminal returns true if the supplied reflect.Kind is a terminal (i.e.
-// primitive) type with no additional sub-fields (e.g. an int, bool, string).
-func isTerminal(kind reflect.Kind) bool {
-	return kind != reflect.Struct && kind != reflect.Slice && kind != reflect.Array
+// isTerminal returns true if a field reported by EachField is a terminal (i.e.
+// primitive) value with no additional sub-fields (e.g. an int, bool, string or
+// list of them), as opposed to a struct or generic object.
+func isTerminal(obj interface{}, kind reflect.Kind) bool {
+	if isCustom(obj) {
+		return false
+	}
+	if kind == reflect.Map {
+		return !isObject(reflect.ValueOf(obj))
+	}
+	return kind != reflect.Struct
 }
 
 // Get the specified dot-path value by digging down and extracting from each
 // component of the dot-path.
-func Get(obj interface{}, dotPath string) interface{} {
-	stack := strings.Split(dotPath, Separator)
-	for len(stack) > 0 {
-		// Pop off front name.
-		obj = getAttr(obj, stack[0])
-		stack = stack[1:]
-
-		switch obj.(type) {
-		case []interface{}:
-			var (
-				objs      = obj.([]interface{})
-				out       = []interface{}{}
-				remainder = strings.Join(stack, Separator)
-			)
-			for _, obj = range objs {
-				out = append(out, Get(obj, remainder))
-			}
-			obj = out
-			return obj
-		}
-	}
-	return obj
+//
+// When a slice or array is followed by further components, Get fans out over
+// each of its elements, resolving the remainder of the path against every one
+// and collecting the results into a []interface{}.  Nil elements yield nil,
+// at any level, keeping the results in step with the elements.  By default the results of nested
+// fan-outs are nested too (e.g. "A.Bs.Cs.Name" yields a list of lists); pass
+// WithFanOut(FanOutFlat) to concatenate them instead.  An index component
+// (e.g. "Contents[2]") selects a single element rather than fanning out, and a
+// "*" component (e.g. "Contents[*].Key") spells the fan-out out explicitly.  A
+// path which ends on a slice or array yields a []interface{} of its elements;
+// pass WithTypedSlices to get the slice or array itself instead.
+//
+// Paths containing the "**" wildcard return a []interface{} of every matching
+// value; see GetWithPaths.
+//
+// Objects implementing StaticGetter resolve the paths they know of without
+// reflection.
+func Get(obj interface{}, dotPath string, opts ...Option) interface{} {
+	return defaultReflector.Get(obj, dotPath, opts...)
 }
 
-func getAttr(obj interface{}, name string) interface{} {
-	if name == "" {
-		return obj
+// Get is the Reflector equivalent of the package-level Get.
+func (r *Reflector) Get(obj interface{}, dotPath string, opts ...Option) interface{} {
+	o := r.options(opts)
+	if g, ok := obj.(StaticGetter); ok && o.fanOut == FanOutNested && !o.typedSlices && o.static(obj) {
+		if value, ok := g.MetaflectorGet(dotPath, o.sep(), o.tagName); ok {
+			return value
+		}
 	}
-	var ok bool
-	if obj, ok = resolvePointer(obj); !ok {
+	components, err := pathComponents(dotPath, o.sep())
+	if err != nil {
 		return nil
 	}
-
-	v := reflect.ValueOf(obj)
-
-	switch v.Kind() {
-	case reflect.Map:
-		return nil
-
-	case reflect.Slice, reflect.Array:
-		out := []interface{}{}
-		eachElement(v, func(_ int, ele reflect.Value) {
-			if ok {
-				if obj, ok = resolvePointer(obj); !ok {
-					return
-				}
-				if kind := ele.Kind(); kind == reflect.Struct || kind == reflect.Slice || kind == reflect.Array || !ele.IsNil() {
-					out = append(out, getAttr(unreflect(ele), name))
-				}
+	if hasRecursiveWildcard(components) {
+		values := []interface{}{}
+		for _, m := range (&walker{r: r, o: o}).resolve(node{v: reflect.ValueOf(obj)}, components, nil) {
+			if !m.Skipped {
+				values = append(values, m.Value)
 			}
-		})
-		if !ok {
-			return nil
 		}
-		obj = out
+		return values
+	}
+	if len(components) == 0 {
 		return obj
 	}
+	value, _ := r.get(reflect.ValueOf(obj), components, o)
+	return value
+}
+
+// get resolves the components against v.  fanned is true when the returned value
+// is a []interface{} collected by fanning out, as opposed to a value which
+// happens to be a []interface{}.
+func (r *Reflector) get(v reflect.Value, components []string, o *options) (value interface{}, fanned bool) {
+	if len(components) == 0 {
+		if !v.IsValid() {
+			return nil, false
+		}
+		if list := elem(v); !o.typedSlices && (list.Kind() == reflect.Slice || list.Kind() == reflect.Array) {
+			return elements(list), false
+		}
+		return unreflect(v), false
+	}
 
 	var (
-		field = v.FieldByName(name)
-		kind  = field.Kind()
+		name = components[0]
+		iv   = indirect(v)
 	)
 
-	if reflect.DeepEqual(field, reflect.Value{}) {
-		return nil
+	if _, getter := customFields(v); getter != nil {
+		if value, ok := getter.MetaflectorField(name); ok {
+			return r.get(reflect.ValueOf(value), components[1:], o)
+		}
 	}
 
-	switch kind {
+	switch iv.Kind() {
 	case reflect.Slice, reflect.Array:
+		if i, isIndex := parseIndex(name); isIndex {
+			if i >= iv.Len() {
+				return nil, false
+			}
+			return r.get(iv.Index(i), components[1:], o)
+		}
+		if name == anyElement {
+			components = components[1:]
+		}
 		out := []interface{}{}
-		eachElement(field, func(_ int, ele reflect.Value) {
-			if ok {
-				if obj, ok = resolvePointer(obj); !ok {
-					return
-				}
-				if isStruct(obj) || !ele.IsNil() {
-					out = append(out, ele.Interface())
-				}
+		eachElement(iv, func(_ int, ele reflect.Value) {
+			// Nil elements have nothing to resolve against.
+			if !indirect(ele).IsValid() {
+				out = append(out, nil)
+				return
+			}
+			value, fanned := r.get(ele, components, o)
+			if fanned && o.fanOut == FanOutFlat {
+				out = append(out, value.([]interface{})...)
+			} else {
+				out = append(out, value)
 			}
 		})
-		obj = out
-	default:
-		obj = unreflect(field)
+		return out, true
+
+	case reflect.Map:
+		// Only string-like keys are addressable by path.
+		_, value := mapIndex(iv, name)
+		if !value.IsValid() {
+			return nil, false
+		}
+		return r.get(value, components[1:], o)
+
+	case reflect.Struct:
+		field := r.fieldByName(iv, name, o.tagName)
+		if !field.IsValid() {
+			return nil, false
+		}
+		return r.get(field, components[1:], o)
 	}
 
-	return obj
+	return nil, false
+}
+
+// elem resolves interfaces, but not pointers.
+func elem(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// elements returns the elements of a slice or array as a []interface{}.
+func elements(v reflect.Value) []interface{} {
+	out := make([]interface{}, 0, v.Len())
+	eachElement(v, func(_ int, ele reflect.Value) {
+		out = append(out, ele.Interface())
+	})
+	return out
 }
 
 // eachElement invokes the callback func on each sub-element of an array or
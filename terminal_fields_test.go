@@ -588,7 +588,7 @@ func TestGet(t *testing.T) {
 				},
 			},
 			path:     "Key",
-			expected: []interface{}{"test1"},
+			expected: []interface{}{nil, "test1"},
 		},
 		{
 			obj: []*Content{
@@ -605,7 +605,7 @@ func TestGet(t *testing.T) {
 				},
 			},
 			path:     "Key",
-			expected: []interface{}{"test1", "test2"},
+			expected: []interface{}{nil, "test1", "test2"},
 		},
 		{
 			obj: [][]*Content{
@@ -634,8 +634,8 @@ func TestGet(t *testing.T) {
 			},
 			path: "Key",
 			expected: []interface{}{
-				[]interface{}{"test1", "test2"},
-				[]interface{}{"test3", "test4"},
+				[]interface{}{nil, "test1", "test2"},
+				[]interface{}{nil, "test3", "test4"},
 			},
 		},
 		{
@@ -665,8 +665,8 @@ func TestGet(t *testing.T) {
 			},
 			path: "Key",
 			expected: []interface{}{
-				[]interface{}{"test1", "test2"},
-				[]interface{}{"test3", "test4"},
+				[]interface{}{nil, "test1", "test2"},
+				[]interface{}{nil, "test3", "test4"},
 			},
 		},
 		{
@@ -784,3 +784,379 @@ func TestGet(t *testing.T) {
 		}
 	}
 }
+
+func TestTerminalFieldsOptions(t *testing.T) {
+	obj := &Foo{
+		Bar: Bar{
+			Baz: Baz{
+				Contents: []Content{
+					{Key: "99"},
+				},
+			},
+		},
+		StructPtr: &Bar{},
+		Contents: []Content{
+			{Key: "hotdog"},
+		},
+	}
+
+	tests := []struct {
+		opts     []Option
+		expected []string
+	}{
+		{
+			opts:     []Option{WithMaxDepth(1)},
+			expected: []string{},
+		},
+		{
+			opts: []Option{WithMaxDepth(2)},
+			expected: []string{
+				"Bar.Stock",
+				"Contents.Key",
+				"Contents.Value",
+				"Contents.Version",
+				"StructPtr.Stock",
+			},
+		},
+		{
+			opts: []Option{WithFilter("Bar.**")},
+			expected: []string{
+				"Bar.Baz.Active",
+				"Bar.Baz.Contents.Key",
+				"Bar.Baz.Contents.Value",
+				"Bar.Baz.Contents.Version",
+				"Bar.Baz.Map",
+				"Bar.Baz.Multiplier",
+				"Bar.Baz.Name",
+				"Bar.Baz.PtrA",
+				"Bar.Baz.PtrB",
+				"Bar.Stock",
+			},
+		},
+		{
+			opts: []Option{WithFilter("**.Key")},
+			expected: []string{
+				"Bar.Baz.Contents.Key",
+				"Contents.Key",
+			},
+		},
+		{
+			opts: []Option{WithFilter("*.Baz.Ptr?", "Contents.V*")},
+			expected: []string{
+				"Bar.Baz.PtrA",
+				"Bar.Baz.PtrB",
+				"Contents.Value",
+				"Contents.Version",
+				"StructPtr.Baz.PtrA",
+				"StructPtr.Baz.PtrB",
+			},
+		},
+		{
+			opts: []Option{WithFilter("!Bar.*", "!StructPtr.Baz", "!*.Version")},
+			expected: []string{
+				"Contents.Key",
+				"Contents.Value",
+				"StructPtr.Stock",
+			},
+		},
+		{
+			opts: []Option{WithFilter("!Contents", "!Bar.Baz.Contents")},
+			expected: []string{
+				"Bar.Baz.Active",
+				"Bar.Baz.Map",
+				"Bar.Baz.Multiplier",
+				"Bar.Baz.Name",
+				"Bar.Baz.PtrA",
+				"Bar.Baz.PtrB",
+				"Bar.Stock",
+				"StructPtr.Baz.Active",
+				"StructPtr.Baz.Map",
+				"StructPtr.Baz.Multiplier",
+				"StructPtr.Baz.Name",
+				"StructPtr.Baz.PtrA",
+				"StructPtr.Baz.PtrB",
+				"StructPtr.Stock",
+			},
+		},
+		{
+			opts: []Option{WithFilter("StructPtr.**", "!**.Baz")},
+			expected: []string{
+				"StructPtr.Stock",
+			},
+		},
+		{
+			opts: []Option{WithExcludedTypes(reflect.TypeOf(Baz{}), reflect.TypeOf(Content{}))},
+			expected: []string{
+				"Bar.Stock",
+				"StructPtr.Stock",
+			},
+		},
+		{
+			opts: []Option{WithMaxDepth(3), WithFilter("!StructPtr"), WithExcludedTypes(reflect.TypeOf(Content{}))},
+			expected: []string{
+				"Bar.Baz.Active",
+				"Bar.Baz.Map",
+				"Bar.Baz.Multiplier",
+				"Bar.Baz.Name",
+				"Bar.Baz.PtrA",
+				"Bar.Baz.PtrB",
+				"Bar.Stock",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.expected, TerminalFields(obj, test.opts...); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected fields=%# v but actual=%# v", i, expected, actual)
+		}
+	}
+}
+
+func TestTerminalFieldsDeclarationOrder(t *testing.T) {
+	obj := &Foo{
+		Bar: Bar{
+			Baz: Baz{
+				Contents: []Content{
+					{Key: "99"},
+				},
+			},
+		},
+		StructPtr: &Bar{},
+		Contents: []Content{
+			{Key: "hotdog"},
+		},
+	}
+
+	expected := []string{
+		"Bar.Baz.Name",
+		"Bar.Baz.Multiplier",
+		"Bar.Baz.Active",
+		"Bar.Baz.Contents.Key",
+		"Bar.Baz.Contents.Value",
+		"Bar.Baz.Contents.Version",
+		"Bar.Baz.Map",
+		"Bar.Baz.PtrA",
+		"Bar.Baz.PtrB",
+		"Bar.Stock",
+		"StructPtr.Baz.Name",
+		"StructPtr.Baz.Multiplier",
+		"StructPtr.Baz.Active",
+		"StructPtr.Baz.Map",
+		"StructPtr.Baz.PtrA",
+		"StructPtr.Baz.PtrB",
+		"StructPtr.Stock",
+		"Contents.Key",
+		"Contents.Value",
+		"Contents.Version",
+	}
+
+	if actual := TerminalFields(obj, WithDeclarationOrder()); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+
+	// Options compose with the declaration ordering.
+	expected = []string{
+		"StructPtr.Baz.Name",
+		"StructPtr.Baz.Multiplier",
+		"StructPtr.Baz.Active",
+		"StructPtr.Stock",
+	}
+
+	if actual := TerminalFields(obj, WithDeclarationOrder(), WithFilter("StructPtr.**", "!**.Map", "!**.Ptr*")); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected fields=%# v but actual=%# v", expected, actual)
+	}
+}
+
+func TestGetFanOut(t *testing.T) {
+	type (
+		C struct {
+			Name string
+		}
+
+		B struct {
+			Cs    []*C
+			Array [2]C
+		}
+
+		A struct {
+			Bs []B
+		}
+	)
+
+	var (
+		a = A{
+			Bs: []B{
+				{
+					Cs:    []*C{{Name: "b0c0"}, nil, {Name: "b0c2"}},
+					Array: [2]C{{Name: "b0a0"}, {Name: "b0a1"}},
+				},
+				{
+					Cs:    []*C{},
+					Array: [2]C{{Name: "b1a0"}, {Name: "b1a1"}},
+				},
+				{
+					Cs: []*C{{Name: "b2c0"}},
+				},
+			},
+		}
+		baz = Baz{
+			ContentPtrs: []*Content{nil, {Key: "p1"}, {Key: "p2"}},
+			PtrContentPtrPtrs: &[]**Content{
+				nil,
+				&notHotdogPtr,
+				nil,
+				&notHotdogPtr,
+			},
+		}
+		grid = [][]*Content{
+			{nil, {Key: "k01"}, {Key: "k02"}},
+			{},
+			{{Key: "k20"}},
+		}
+	)
+
+	tests := []struct {
+		obj    interface{}
+		path   string
+		nested interface{}
+		flat   interface{}
+	}{
+		{
+			obj:  a,
+			path: "Bs.Cs.Name",
+			nested: []interface{}{
+				[]interface{}{"b0c0", nil, "b0c2"},
+				[]interface{}{},
+				[]interface{}{"b2c0"},
+			},
+			flat: []interface{}{"b0c0", nil, "b0c2", "b2c0"},
+		},
+		{
+			obj:  a,
+			path: "Bs.Array.Name",
+			nested: []interface{}{
+				[]interface{}{"b0a0", "b0a1"},
+				[]interface{}{"b1a0", "b1a1"},
+				[]interface{}{"", ""},
+			},
+			flat: []interface{}{"b0a0", "b0a1", "b1a0", "b1a1", "", ""},
+		},
+		{
+			obj:    a,
+			path:   "Bs[0].Cs.Name",
+			nested: []interface{}{"b0c0", nil, "b0c2"},
+			flat:   []interface{}{"b0c0", nil, "b0c2"},
+		},
+		{
+			obj:    a,
+			path:   "Bs.Cs[0].Name",
+			nested: []interface{}{"b0c0", nil, "b2c0"},
+			flat:   []interface{}{"b0c0", nil, "b2c0"},
+		},
+		{
+			obj:  a,
+			path: "Bs.Array",
+			nested: []interface{}{
+				[]interface{}{C{Name: "b0a0"}, C{Name: "b0a1"}},
+				[]interface{}{C{Name: "b1a0"}, C{Name: "b1a1"}},
+				[]interface{}{C{}, C{}},
+			},
+			flat: []interface{}{
+				[]interface{}{C{Name: "b0a0"}, C{Name: "b0a1"}},
+				[]interface{}{C{Name: "b1a0"}, C{Name: "b1a1"}},
+				[]interface{}{C{}, C{}},
+			},
+		},
+		{
+			obj:    &a,
+			path:   "Bs[2].Cs",
+			nested: []interface{}{a.Bs[2].Cs[0]},
+			flat:   []interface{}{a.Bs[2].Cs[0]},
+		},
+		{
+			obj:  grid,
+			path: "[*][*].Key",
+			nested: []interface{}{
+				[]interface{}{nil, "k01", "k02"},
+				[]interface{}{},
+				[]interface{}{"k20"},
+			},
+			flat: []interface{}{nil, "k01", "k02", "k20"},
+		},
+		{
+			obj:    baz,
+			path:   "ContentPtrs.Key",
+			nested: []interface{}{nil, "p1", "p2"},
+			flat:   []interface{}{nil, "p1", "p2"},
+		},
+		{
+			obj:    &baz,
+			path:   "PtrContentPtrPtrs.Value",
+			nested: []interface{}{nil, "hotdog", nil, "hotdog"},
+			flat:   []interface{}{nil, "hotdog", nil, "hotdog"},
+		},
+		{
+			obj:    baz,
+			path:   "PtrContentPtrPtrs[3].Key",
+			nested: "not",
+			flat:   "not",
+		},
+		{
+			obj:  grid,
+			path: "Key",
+			nested: []interface{}{
+				[]interface{}{nil, "k01", "k02"},
+				[]interface{}{},
+				[]interface{}{"k20"},
+			},
+			flat: []interface{}{nil, "k01", "k02", "k20"},
+		},
+		{
+			obj:    &grid,
+			path:   "[0].Key",
+			nested: []interface{}{nil, "k01", "k02"},
+			flat:   []interface{}{nil, "k01", "k02"},
+		},
+		{
+			obj:    [2]*Content{{Key: "arr0"}, nil},
+			path:   "Key",
+			nested: []interface{}{"arr0", nil},
+			flat:   []interface{}{"arr0", nil},
+		},
+		{
+			obj:    []Content{{Key: "a"}},
+			path:   "Missing",
+			nested: []interface{}{nil},
+			flat:   []interface{}{nil},
+		},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.nested, Get(test.obj, test.path); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected default value=%# v but actual=%# v for path=%q", i, expected, actual, test.path)
+		}
+		if expected, actual := test.nested, Get(test.obj, test.path, WithFanOut(FanOutNested)); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected nested value=%# v but actual=%# v for path=%q", i, expected, actual, test.path)
+		}
+		if expected, actual := test.flat, Get(test.obj, test.path, WithFanOut(FanOutFlat)); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected flat value=%# v but actual=%# v for path=%q", i, expected, actual, test.path)
+		}
+	}
+
+	// A Reflector may default to flattening, overridable per call.
+	r := New(WithFanOut(FanOutFlat))
+	if expected, actual := []interface{}{nil, "k01", "k02", "k20"}, r.Get(grid, "Key"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected value=%# v but actual=%# v", expected, actual)
+	}
+	if expected, actual := 3, len(r.Get(grid, "Key", WithFanOut(FanOutNested)).([]interface{})); actual != expected {
+		t.Errorf("Expected %v lists but actual=%v", expected, actual)
+	}
+
+	// Slices and arrays at the end of a path may be returned as they are.
+	if expected, actual := a.Bs[2].Cs, Get(a, "Bs[2].Cs", WithTypedSlices()); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected value=%# v but actual=%# v", expected, actual)
+	}
+	if expected, actual := []interface{}{a.Bs[0].Array, a.Bs[1].Array, a.Bs[2].Array}, Get(a, "Bs.Array", WithTypedSlices()); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected value=%# v but actual=%# v", expected, actual)
+	}
+}
@@ -0,0 +1,506 @@
+package metaflector
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// validateTag is the struct tag from which TagRules reads rules.
+const validateTag = "validate"
+
+// ErrInvalidRule is returned when a validation rule in a struct tag cannot be
+// parsed, or when a Rule wasn't created by one of the constructors.
+var ErrInvalidRule = errors.New("invalid validation rule")
+
+// Rule is a constraint applied to the value(s) found at a path.  Rules are
+// created with Required, Min, Max, Len, Regex and Enum, or read from struct
+// tags with TagRules.  Rule literals carry no check to apply, so Validate
+// rejects them with ErrInvalidRule unless their Name is "required".
+//
+// The path follows the same syntax as Get, so "Contents[*].Key" applies the
+// rule to the Key of every element of Contents.
+type Rule struct {
+	Path  string
+	Name  string // e.g. "min".
+	Param string // e.g. "0".
+
+	check func(v reflect.Value) (bool, error)
+}
+
+// Violation describes a value which failed a Rule, along with the concrete
+// path at which it was found, e.g. "Contents[2].Key".
+type Violation struct {
+	Path  string
+	Rule  string
+	Param string
+	Value interface{}
+}
+
+func (v Violation) Error() string {
+	var msg string
+	switch v.Rule {
+	case "required":
+		msg = "is required"
+	case "min":
+		msg = "must be at least " + v.Param
+	case "max":
+		msg = "must be at most " + v.Param
+	case "len":
+		msg = "must have length " + v.Param
+	case "regex":
+		msg = "must match " + strconv.Quote(v.Param)
+	case "enum":
+		msg = "must be one of " + strings.Replace(v.Param, "|", ", ", -1)
+	default:
+		msg = "violates " + v.Rule + "=" + v.Param
+	}
+	return v.Path + ": " + msg
+}
+
+// Required returns a rule which is violated when the path can't be followed
+// (e.g. through a nil pointer or missing map key) or ends on a nil pointer,
+// an empty string, slice or map, or any other zero value.
+func Required(path string) Rule {
+	return Rule{
+		Path: path,
+		Name: "required",
+		check: func(v reflect.Value) (bool, error) {
+			return !isEmpty(v), nil
+		},
+	}
+}
+
+// Min returns a rule which is violated by numbers less than min, or strings,
+// slices, arrays and maps with a length less than min.
+func Min(path string, min float64) Rule {
+	return Rule{
+		Path:  path,
+		Name:  "min",
+		Param: strconv.FormatFloat(min, 'g', -1, 64),
+		check: func(v reflect.Value) (bool, error) {
+			f, err := magnitude(v)
+			return f >= min, err
+		},
+	}
+}
+
+// Max returns a rule which is violated by numbers greater than max, or
+// strings, slices, arrays and maps with a length greater than max.
+func Max(path string, max float64) Rule {
+	return Rule{
+		Path:  path,
+		Name:  "max",
+		Param: strconv.FormatFloat(max, 'g', -1, 64),
+		check: func(v reflect.Value) (bool, error) {
+			f, err := magnitude(v)
+			return f <= max, err
+		},
+	}
+}
+
+// Len returns a rule which is violated by strings, slices, arrays and maps
+// whose length isn't exactly n.
+func Len(path string, n int) Rule {
+	return Rule{
+		Path:  path,
+		Name:  "len",
+		Param: strconv.Itoa(n),
+		check: func(v reflect.Value) (bool, error) {
+			switch v.Kind() {
+			case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+				return v.Len() == n, nil
+			}
+			return false, ErrTypeMismatch
+		},
+	}
+}
+
+// Regex returns a rule which is violated by strings not matching re.
+func Regex(path string, re *regexp.Regexp) Rule {
+	return Rule{
+		Path:  path,
+		Name:  "regex",
+		Param: re.String(),
+		check: func(v reflect.Value) (bool, error) {
+			if v.Kind() != reflect.String {
+				return false, ErrTypeMismatch
+			}
+			return re.MatchString(v.String()), nil
+		},
+	}
+}
+
+// Enum returns a rule which is violated by values not amongst those given.
+// Values are compared by their default string formatting, so Enum(path, 1)
+// accepts a uint8 of 1 as well as the string "1".
+func Enum(path string, values ...interface{}) Rule {
+	allowed := make([]string, len(values))
+	for i, value := range values {
+		allowed[i] = fmt.Sprint(value)
+	}
+	return Rule{
+		Path:  path,
+		Name:  "enum",
+		Param: strings.Join(allowed, "|"),
+		check: func(v reflect.Value) (bool, error) {
+			s := fmt.Sprint(v.Interface())
+			for _, a := range allowed {
+				if s == a {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	}
+}
+
+// Validate evaluates the rules against obj, returning a Violation for every
+// value which fails a rule, ordered by rule and then by traversal order.
+//
+// Only Required is applied to paths which can't be followed or end on a nil
+// pointer; the remaining rules skip such values, so combine them with
+// Required as necessary.  An error is returned if a rule's path can't be
+// parsed, or if a rule doesn't apply to the kind of value found (e.g. Regex
+// on an int).
+func Validate(obj interface{}, rules ...Rule) ([]Violation, error) {
+	return defaultReflector.Validate(obj, rules...)
+}
+
+// Validate is the Reflector equivalent of the package-level Validate.
+func (r *Reflector) Validate(obj interface{}, rules ...Rule) ([]Violation, error) {
+	var (
+		o          = r.options(nil)
+		w          = &walker{r: r, o: o}
+		violations = []Violation{}
+	)
+
+	for _, rule := range rules {
+		if rule.check == nil && rule.Name != "required" {
+			return nil, &PathError{Path: rule.Path, Err: ErrInvalidRule}
+		}
+		components, err := pathComponents(rule.Path, o.sep())
+		if err != nil {
+			return nil, &PathError{Path: rule.Path, Err: err}
+		}
+		for _, t := range w.targets(node{v: reflect.ValueOf(obj)}, components, nil) {
+			var (
+				v     = indirect(t.v)
+				value interface{}
+			)
+			if v.IsValid() && v.CanInterface() {
+				value = v.Interface()
+			}
+			if rule.Name == "required" {
+				if t.missing || isEmpty(t.v) {
+					violations = append(violations, Violation{Path: t.path, Rule: rule.Name, Param: rule.Param, Value: value})
+				}
+				continue
+			}
+			if t.missing || !v.IsValid() {
+				continue
+			}
+			ok, err := rule.check(v)
+			if err != nil {
+				return nil, &PathError{Path: t.path, Err: err}
+			}
+			if !ok {
+				violations = append(violations, Violation{Path: t.path, Rule: rule.Name, Param: rule.Param, Value: value})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// TagRules collects the rules declared in the "validate" struct tags of obj's
+// type, e.g.
+//
+//	type Config struct {
+//		Name     string   `validate:"required,len=3"`
+//		Level    int      `validate:"min=0,max=100"`
+//		Mode     string   `validate:"enum=fast|slow"`
+//		Contents []Content
+//	}
+//
+//	type Content struct {
+//		Key string `validate:"required,regex=^[a-z]+$"`
+//	}
+//
+// yields rules for "Name", "Level", "Mode" and "Contents[*].Key".  As regular
+// expressions may contain commas, regex consumes the remainder of the tag and
+// must come last.  Map values and interfaces aren't descended into, and
+// recursive types are expanded only once per branch.
+func TagRules(obj interface{}) ([]Rule, error) {
+	return defaultReflector.TagRules(obj)
+}
+
+// TagRules is the Reflector equivalent of the package-level TagRules.
+func (r *Reflector) TagRules(obj interface{}) ([]Rule, error) {
+	if obj == nil {
+		return []Rule{}, nil
+	}
+	return r.tagRules(reflect.TypeOf(obj), "", r.options(nil), map[reflect.Type]struct{}{}, []Rule{})
+}
+
+// ValidateTags validates obj against the rules declared in its struct tags.
+// See TagRules and Validate.
+func ValidateTags(obj interface{}) ([]Violation, error) {
+	return defaultReflector.ValidateTags(obj)
+}
+
+// ValidateTags is the Reflector equivalent of the package-level ValidateTags.
+func (r *Reflector) ValidateTags(obj interface{}) ([]Violation, error) {
+	rules, err := r.TagRules(obj)
+	if err != nil {
+		return nil, err
+	}
+	return r.Validate(obj, rules...)
+}
+
+func (r *Reflector) tagRules(t reflect.Type, path string, o *options, seen map[reflect.Type]struct{}, rules []Rule) ([]Rule, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	sep := o.sep()
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := seen[t]; ok {
+			return rules, nil
+		}
+		seen[t] = struct{}{}
+		defer delete(seen, t)
+
+		for _, f := range r.fields(t, o.tagName) {
+			var (
+				fieldPath = childPath(path, quoteComponent(f.name, sep), sep)
+				err       error
+			)
+			if tag := t.Field(f.index).Tag.Get(validateTag); tag != "" {
+				if rules, err = parseRules(fieldPath, tag, rules); err != nil {
+					return nil, err
+				}
+			}
+			if rules, err = r.tagRules(f.typ, fieldPath, o, seen, rules); err != nil {
+				return nil, err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		return r.tagRules(t.Elem(), childPath(path, "[*]", sep), o, seen, rules)
+	}
+
+	return rules, nil
+}
+
+// parseRules appends the rules found in a "validate" struct tag.
+func parseRules(path string, tag string, rules []Rule) ([]Rule, error) {
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			part, tag = tag[:i], tag[i+1:]
+		} else {
+			part, tag = tag, ""
+		}
+
+		var (
+			kv    = strings.SplitN(part, "=", 2)
+			name  = strings.TrimSpace(kv[0])
+			param string
+		)
+		if len(kv) == 2 {
+			param = kv[1]
+		}
+
+		switch name {
+		case "":
+			continue
+
+		case "required":
+			rules = append(rules, Required(path))
+
+		case "min", "max":
+			f, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, &PathError{Path: path, Err: ErrInvalidRule}
+			}
+			if name == "min" {
+				rules = append(rules, Min(path, f))
+			} else {
+				rules = append(rules, Max(path, f))
+			}
+
+		case "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				return nil, &PathError{Path: path, Err: ErrInvalidRule}
+			}
+			rules = append(rules, Len(path, n))
+
+		case "regex":
+			re, err := regexp.Compile(param)
+			if err != nil {
+				return nil, &PathError{Path: path, Err: ErrInvalidRule}
+			}
+			rules = append(rules, Regex(path, re))
+
+		case "enum":
+			values := []interface{}{}
+			for _, value := range strings.Split(param, "|") {
+				values = append(values, value)
+			}
+			rules = append(rules, Enum(path, values...))
+
+		default:
+			return nil, &PathError{Path: path, Err: ErrInvalidRule}
+		}
+	}
+	return rules, nil
+}
+
+// target is a value addressed by a rule's path.  Missing targets are those
+// where the path couldn't be followed, in which case the path is completed
+// from the remaining components.
+type target struct {
+	node
+	missing bool
+}
+
+// targets resolves the components from n in the same manner as resolve, but
+// also reports the places where resolution stopped short.
+func (w *walker) targets(n node, components []string, out []target) []target {
+	if len(components) == 0 {
+		return append(out, target{node: n})
+	}
+
+	name := components[0]
+
+	if name == anyDescendant {
+		for _, m := range w.descend(n, components[1:], nil) {
+			out = append(out, target{node: node{v: reflect.ValueOf(m.Value), path: m.Path}})
+		}
+		return out
+	}
+
+	v := indirect(n.v)
+	if !v.IsValid() {
+		return append(out, w.missing(n.path, components))
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if i, ok := parseIndex(name); ok {
+			if i >= v.Len() {
+				return append(out, w.missing(n.path, components))
+			}
+			return w.targets(w.element(n, v, i), components[1:], out)
+		}
+		rest := components
+		if name == anyElement {
+			rest = components[1:]
+		}
+		for i := 0; i < v.Len(); i++ {
+			out = w.targets(w.element(n, v, i), rest, out)
+		}
+		return out
+	}
+
+	if child, ok := w.child(n, name); ok {
+		return w.targets(child, components[1:], out)
+	}
+	return append(out, w.missing(n.path, components))
+}
+
+// missing returns a missing target whose path is completed by the remaining
+// components.
+func (w *walker) missing(path string, components []string) target {
+	sep := w.o.sep()
+	for _, c := range components {
+		if c == anyElement {
+			c = "[*]"
+		} else if c == newElement {
+			c = "-"
+		} else if _, ok := parseIndex(c); ok {
+			c = "[" + c + "]"
+		} else {
+			c = quoteComponent(c, sep)
+		}
+		path = childPath(path, c, sep)
+	}
+	return target{node: node{path: path}, missing: true}
+}
+
+// isEmpty returns true for invalid values, nil pointers and interfaces, empty
+// strings, slices and maps, and other zero values.
+func isEmpty(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return isZero(v)
+}
+
+// isZero returns true if v holds the zero value for its type, in the same way
+// as reflect.Value.IsZero, which needs Go 1.13.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return math.Float64bits(v.Float()) == 0
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return math.Float64bits(real(c)) == 0 && math.Float64bits(imag(c)) == 0
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isZero(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isZero(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.UnsafePointer:
+		return v.Pointer() == 0
+	}
+	return isNil(v)
+}
+
+// magnitude returns the numeric value, or length, of v.
+func magnitude(v reflect.Value) (float64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), nil
+	}
+	return 0, ErrTypeMismatch
+}
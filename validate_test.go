@@ -0,0 +1,281 @@
+package metaflector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	obj := &Foo{
+		Bar: Bar{
+			Baz: Baz{
+				Name:       "baz",
+				Multiplier: 150,
+				Contents: []Content{
+					{Key: "c0", Value: "v0"},
+					{Key: "", Value: "v1"},
+					{Key: "C2", Value: "v2"},
+				},
+				ContentPtrs: []*Content{
+					{Key: "p0"},
+					nil,
+				},
+				Map: map[string]string{
+					"a": "1",
+				},
+				PtrA: &uEight,
+			},
+		},
+	}
+
+	tests := []struct {
+		rules    []Rule
+		expected []Violation
+	}{
+		{
+			rules: []Rule{Min("Bar.Baz.Multiplier", 0), Max("Bar.Baz.Multiplier", 100)},
+			expected: []Violation{
+				{Path: "Bar.Baz.Multiplier", Rule: "max", Param: "100", Value: float64(150)},
+			},
+		},
+		{
+			rules: []Rule{Required("Bar.Baz.Contents[*].Key")},
+			expected: []Violation{
+				{Path: "Bar.Baz.Contents[1].Key", Rule: "required", Value: ""},
+			},
+		},
+		{
+			// The wildcard is implied by a field name following a slice.
+			rules: []Rule{Regex("Bar.Baz.Contents.Key", regexp.MustCompile("^[a-z][0-9]$"))},
+			expected: []Violation{
+				{Path: "Bar.Baz.Contents[1].Key", Rule: "regex", Param: "^[a-z][0-9]$", Value: ""},
+				{Path: "Bar.Baz.Contents[2].Key", Rule: "regex", Param: "^[a-z][0-9]$", Value: "C2"},
+			},
+		},
+		{
+			rules: []Rule{Required("Bar.Baz.ContentPtrs[*].Key"), Len("Bar.Baz.ContentPtrs[*].Key", 3)},
+			expected: []Violation{
+				{Path: "Bar.Baz.ContentPtrs[1].Key", Rule: "required"},
+				{Path: "Bar.Baz.ContentPtrs[0].Key", Rule: "len", Param: "3", Value: "p0"},
+			},
+		},
+		{
+			rules: []Rule{Required("StructPtr.Baz.Name"), Min("StructPtr.Baz.Multiplier", 1)},
+			expected: []Violation{
+				{Path: "StructPtr.Baz.Name", Rule: "required"},
+			},
+		},
+		{
+			rules: []Rule{Required("Bar.Baz.Map.a"), Required("Bar.Baz.Map.b"), Required("Bar.Baz.Contents[5]")},
+			expected: []Violation{
+				{Path: "Bar.Baz.Map.b", Rule: "required"},
+				{Path: "Bar.Baz.Contents[5]", Rule: "required"},
+			},
+		},
+		{
+			rules: []Rule{Enum("Bar.Baz.Name", "foo", "bar"), Enum("Bar.Baz.PtrA", 8, 9), Enum("Bar.Baz.Active", false)},
+			expected: []Violation{
+				{Path: "Bar.Baz.Name", Rule: "enum", Param: "foo|bar", Value: "baz"},
+			},
+		},
+		{
+			rules: []Rule{Min("Bar.Baz.Contents", 4), Max("Bar.Baz.Name", 2), Required("Bar.Baz.PtrB")},
+			expected: []Violation{
+				{Path: "Bar.Baz.Contents", Rule: "min", Param: "4", Value: obj.Bar.Baz.Contents},
+				{Path: "Bar.Baz.Name", Rule: "max", Param: "2", Value: "baz"},
+				{Path: "Bar.Baz.PtrB", Rule: "required"},
+			},
+		},
+		{
+			rules:    []Rule{Required("Bar.Baz.Name"), Required("Contents[*].Key")},
+			expected: []Violation{},
+		},
+	}
+
+	for i, test := range tests {
+		violations, err := Validate(obj, test.rules...)
+		if err != nil {
+			t.Errorf("[i=%v] Unexpected error: %s", i, err)
+			continue
+		}
+		if expected, actual := test.expected, violations; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected violations=%# v but actual=%# v", i, expected, actual)
+		}
+	}
+
+	if _, err := Validate(obj, Regex("Bar.Baz.Multiplier", regexp.MustCompile("."))); err == nil {
+		t.Errorf("Expected type mismatch error for regex rule on a float")
+	}
+	if _, err := Validate(obj, Required(`Bar["unterminated`)); err == nil {
+		t.Errorf("Expected error for invalid path")
+	}
+
+	// Rules built by hand have no check to apply.
+	if _, err := Validate(obj, Rule{Path: "Bar.Baz.Name", Name: "min", Param: "1"}); err == nil || err.(*PathError).Err != ErrInvalidRule {
+		t.Errorf("Expected invalid rule error for a Rule literal but got %v", err)
+	}
+	if violations, err := Validate(obj, Rule{Path: "Bar.Baz.PtrB", Name: "required"}); err != nil || len(violations) != 1 {
+		t.Errorf("Expected a required Rule literal to apply but actual=%v err=%v", violations, err)
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	var (
+		empty = ""
+		zero  = Content{}
+	)
+
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{false, true},
+		{true, false},
+		{0, true},
+		{uint8(1), false},
+		{0.0, true},
+		{-0.5, false},
+		{complex(0, 0), true},
+		{"", true},
+		{"x", false},
+		{[2]int{}, true},
+		{[2]int{0, 1}, false},
+		{zero, true},
+		{Content{Version: 1}, false},
+		{(*string)(nil), true},
+		{&empty, false},
+		{[]int(nil), true},
+		{[]int{}, false},
+		{map[string]int(nil), true},
+		{(func())(nil), true},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.expected, isZero(reflect.ValueOf(test.value)); actual != expected {
+			t.Errorf("[i=%v] Expected zero=%v but actual=%v for value=%#v", i, expected, actual, test.value)
+		}
+	}
+}
+
+func TestViolationError(t *testing.T) {
+	tests := []struct {
+		violation Violation
+		expected  string
+	}{
+		{Violation{Path: "A.B", Rule: "required"}, "A.B: is required"},
+		{Violation{Path: "A.B", Rule: "min", Param: "0"}, "A.B: must be at least 0"},
+		{Violation{Path: "A.B", Rule: "max", Param: "1.5"}, "A.B: must be at most 1.5"},
+		{Violation{Path: "A[0]", Rule: "len", Param: "3"}, "A[0]: must have length 3"},
+		{Violation{Path: "A", Rule: "regex", Param: "^a$"}, `A: must match "^a$"`},
+		{Violation{Path: "A", Rule: "enum", Param: "x|y"}, "A: must be one of x, y"},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.expected, test.violation.Error(); actual != expected {
+			t.Errorf("[i=%v] Expected error=%q but actual=%q", i, expected, actual)
+		}
+	}
+}
+
+func TestTagRules(t *testing.T) {
+	type (
+		Item struct {
+			Key   string `json:"key" validate:"required,regex=^[a-z]{1,3}$"`
+			Count int    `json:"count" validate:"min=1,max=10"`
+		}
+
+		Node struct {
+			Name     string  `validate:"required"`
+			Children []*Node `validate:"max=2"`
+		}
+
+		Config struct {
+			Name  string   `json:"name" validate:"required,len=4"`
+			Mode  string   `json:"mode" validate:"enum=fast|slow"`
+			Items []Item   `json:"items" validate:"min=1"`
+			Grid  [][]Item `json:"grid"`
+			Root  *Node    `json:"root"`
+			Skip  string   `json:"-" validate:"required"`
+		}
+	)
+
+	paths := func(rules []Rule) []string {
+		out := []string{}
+		for _, rule := range rules {
+			out = append(out, rule.Path+" "+rule.Name+"="+rule.Param)
+		}
+		return out
+	}
+
+	r := New(WithTagName("json"))
+
+	rules, err := r.TagRules(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"name required=",
+		"name len=4",
+		"mode enum=fast|slow",
+		"items min=1",
+		"items[*].key required=",
+		"items[*].key regex=^[a-z]{1,3}$",
+		"items[*].count min=1",
+		"items[*].count max=10",
+		"grid[*][*].key required=",
+		"grid[*][*].key regex=^[a-z]{1,3}$",
+		"grid[*][*].count min=1",
+		"grid[*][*].count max=10",
+		"root.Name required=",
+		"root.Children max=2",
+	}
+	if actual := paths(rules); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected rules=%# v but actual=%# v", expected, actual)
+	}
+
+	cfg := &Config{
+		Name: "abc",
+		Mode: "medium",
+		Items: []Item{
+			{Key: "ok", Count: 1},
+			{Key: "TOOLONG", Count: 11},
+		},
+		Grid: [][]Item{
+			nil,
+			{{Count: 5}},
+		},
+		Root: &Node{
+			Name:     "root",
+			Children: []*Node{{Name: "a"}, {}, {Name: "c"}},
+		},
+	}
+
+	violations, err := r.ValidateTags(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := []string{}
+	for _, violation := range violations {
+		actual = append(actual, violation.Error())
+	}
+	expected = []string{
+		"name: must have length 4",
+		"mode: must be one of fast, slow",
+		`items[1].key: must match "^[a-z]{1,3}$"`,
+		"items[1].count: must be at most 10",
+		"grid[1][0].key: is required",
+		`grid[1][0].key: must match "^[a-z]{1,3}$"`,
+		"root.Children: must be at most 2",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected violations=%# v but actual=%# v", expected, actual)
+	}
+
+	type Bad struct {
+		Level int `validate:"min=low"`
+	}
+	if _, err := TagRules(Bad{}); err == nil || err.(*PathError).Err != ErrInvalidRule || err.(*PathError).Path != "Level" {
+		t.Errorf("Expected invalid rule error for Level but got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package metaflector
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+// DefaultMaxIndex is the largest slice index DecodeValues grows a slice to
+// reach unless WithMaxIndex says otherwise.  Indexes come straight from the
+// query string, so without a limit a single key could allocate a slice of any
+// length.
+const DefaultMaxIndex = 10000
+
+// ErrIndexTooLarge is returned by DecodeValues for a slice index beyond the
+// maximum.
+var ErrIndexTooLarge = errors.New("index too large")
+
+// DecodeValues assigns url.Values, such as a parsed query string or form, to
+// the fields of obj named by their dot-path keys, e.g.
+// "?Bar.Baz.Active=true&Contents[0].Key=99".  obj must be a non-nil pointer.
+//
+// Values are parsed according to the destination's type, as they are by
+// LoadEnv.  Repeated keys fill slices, so "Hosts=a&Hosts=b" yields
+// []string{"a", "b"}, and when a key passes through a slice without an index
+// each value is assigned to the corresponding element, so
+// "Contents.Key=a&Contents.Key=b" sets the Key of the first two elements.
+// Slices are grown and nil pointers and maps allocated as needed.  For other
+// fields only the first value is used.
+//
+// Indexes beyond both the current length of a slice and DefaultMaxIndex (see
+// WithMaxIndex) are rejected with ErrIndexTooLarge rather than allocated.
+//
+// Keys which don't name a field of obj are ignored, as query strings often
+// carry parameters meant for something else; pass WithStrictKeys to reject
+// them with ErrNotFound instead.
+func DecodeValues(values url.Values, obj interface{}, opts ...Option) error {
+	return defaultReflector.DecodeValues(values, obj, opts...)
+}
+
+// DecodeValues is the Reflector equivalent of the package-level
+// DecodeValues.
+func (r *Reflector) DecodeValues(values url.Values, obj interface{}, opts ...Option) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrNotSettable
+	}
+
+	o := r.options(opts)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if len(values[key]) == 0 {
+			continue
+		}
+		components, err := pathComponents(key, o.sep())
+		if err != nil {
+			return &PathError{Path: key, Err: err}
+		}
+		// Checked up front so that unknown keys leave obj untouched.
+		if !o.strictKeys && !r.hasField(v.Type(), components, o) {
+			continue
+		}
+		if err := r.decode(v.Elem(), components, values[key], o); err != nil {
+			return &PathError{Path: key, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// hasField returns true unless the components pass through a struct without
+// a field of the given name.  Nothing else is checked, so decode reports any
+// other problems.
+func (r *Reflector) hasField(t reflect.Type, components []string, o *options) bool {
+	for len(components) > 0 {
+		switch t = indirectType(t); t.Kind() {
+		case reflect.Struct:
+			field := r.fieldByName(reflect.New(t).Elem(), components[0], o.tagName)
+			if !field.IsValid() {
+				return false
+			}
+			t, components = field.Type(), components[1:]
+
+		case reflect.Map:
+			t, components = t.Elem(), components[1:]
+
+		case reflect.Slice, reflect.Array:
+			// Field names apply to each element.
+			if _, isIndex := parseIndex(components[0]); isIndex || components[0] == anyElement {
+				components = components[1:]
+			}
+			t = t.Elem()
+
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+func (r *Reflector) decode(v reflect.Value, components []string, values []string, o *options) error {
+	if len(components) == 0 {
+		t := v.Type()
+		if !parsable(t) {
+			return ErrTypeMismatch
+		}
+		if isList(t) {
+			// Each repeated value is an element of the slice.
+			list := reflect.MakeSlice(t, 0, len(values))
+			for _, s := range values {
+				elem, err := parseValue(t.Elem(), s, o.delim())
+				if err != nil {
+					return err
+				}
+				list = reflect.Append(list, elem)
+			}
+			return assign(v, list.Interface())
+		}
+		value, err := parseValue(t, values[0], o.delim())
+		if err != nil {
+			return err
+		}
+		return assign(v, value.Interface())
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		field := r.fieldByName(v, components[0], o.tagName)
+		if !field.IsValid() {
+			return ErrNotFound
+		}
+		if !field.CanSet() {
+			return ErrNotSettable
+		}
+		return r.decode(field, components[1:], values, o)
+
+	case reflect.Map:
+		keyType := v.Type().Key()
+		if keyType.Kind() != reflect.String {
+			return ErrNotFound
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		var (
+			key  = reflect.ValueOf(components[0]).Convert(keyType)
+			elem = reflect.New(v.Type().Elem()).Elem()
+		)
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := r.decode(elem, components[1:], values, o); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if i, isIndex := parseIndex(components[0]); isIndex {
+			if i >= v.Len() && i > o.maxIdx() {
+				return ErrIndexTooLarge
+			}
+			if err := grow(v, i+1); err != nil {
+				return err
+			}
+			return r.decode(v.Index(i), components[1:], values, o)
+		}
+		rest := components
+		if components[0] == anyElement {
+			rest = components[1:]
+		}
+		// Spread the values across the elements.
+		if err := grow(v, len(values)); err != nil {
+			return err
+		}
+		for i, s := range values {
+			if err := r.decode(v.Index(i), rest, []string{s}, o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return ErrNotFound
+}
+
+// grow ensures slice v has at least n elements.  Arrays can't grow, so
+// ErrNotFound is returned if they're too short.
+func grow(v reflect.Value, n int) error {
+	if v.Len() >= n {
+		return nil
+	}
+	if v.Kind() == reflect.Array {
+		return ErrNotFound
+	}
+	v.Set(reflect.AppendSlice(v, reflect.MakeSlice(v.Type(), n-v.Len(), n-v.Len())))
+	return nil
+}
+
+// isList returns true for slice types whose elements are parsed individually,
+// as opposed to those parsed as a whole such as []byte and net.IP.
+func isList(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// EncodeValues returns the terminal fields of obj as url.Values keyed by
+// their concrete paths, such that DecodeValues restores them.  Slices of
+// parsable values become repeated keys, while elements of other slices are
+// addressed by index, e.g. "Contents[0].Key".  Nil pointers are omitted.
+func EncodeValues(obj interface{}) url.Values {
+	return defaultReflector.EncodeValues(obj)
+}
+
+// EncodeValues is the Reflector equivalent of the package-level
+// EncodeValues.
+func (r *Reflector) EncodeValues(obj interface{}) url.Values {
+	var (
+		w      = &walker{r: r, o: r.options(nil)}
+		values = url.Values{}
+	)
+	w.encode(node{v: reflect.ValueOf(obj)}, values, nil)
+	return values
+}
+
+func (w *walker) encode(n node, values url.Values, ancestors []uintptr) {
+	v := n.v
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Ptr {
+			if containsPointer(ancestors, v.Pointer()) {
+				return
+			}
+			ancestors = append(ancestors[:len(ancestors):len(ancestors)], v.Pointer())
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return
+	}
+
+	if parsable(v.Type()) {
+		if isList(v.Type()) {
+			for i := 0; i < v.Len(); i++ {
+				if elem := indirect(v.Index(i)); elem.IsValid() {
+					values.Add(n.path, formatValue(elem))
+				}
+			}
+			return
+		}
+		values.Add(n.path, formatValue(v))
+		return
+	}
+
+	for _, child := range w.children(n) {
+		w.encode(child, values, ancestors)
+	}
+}
@@ -0,0 +1,199 @@
+package metaflector
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	ValuesRequest struct {
+		Query   string
+		Limit   *int
+		Since   time.Time
+		Tags    []string
+		Sizes   []float64
+		Filter  ValuesFilter
+		Items   []ValuesItem
+		Refs    []*ValuesItem
+		Labels  map[string]string
+		Pair    [2]int
+		Timeout time.Duration
+	}
+
+	ValuesFilter struct {
+		Active *bool
+		Min    uint8
+	}
+
+	ValuesItem struct {
+		Key   string
+		Count int
+	}
+)
+
+func TestDecodeValues(t *testing.T) {
+	values, err := url.ParseQuery("Query=shoes&Limit=10&Since=2017-06-01T00:00:00Z" +
+		"&Tags=a&Tags=b&Sizes=9.5&Sizes=10" +
+		"&Filter.Active=true&Filter.Min=3" +
+		"&Items.Key=x&Items.Key=y&Items.Count=1" +
+		"&Refs[1].Key=second" +
+		"&Labels.env=prod&Labels[%22example.com%2Fowner%22]=ops" +
+		"&Pair[1]=2&Timeout=5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		req    = &ValuesRequest{Tags: []string{"default"}}
+		limit  = 10
+		active = true
+	)
+	if err := DecodeValues(values, req); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &ValuesRequest{
+		Query: "shoes",
+		Limit: &limit,
+		Since: time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC),
+		Tags:  []string{"a", "b"},
+		Sizes: []float64{9.5, 10},
+		Filter: ValuesFilter{
+			Active: &active,
+			Min:    3,
+		},
+		Items: []ValuesItem{
+			{Key: "x", Count: 1},
+			{Key: "y"},
+		},
+		Refs: []*ValuesItem{
+			nil,
+			{Key: "second"},
+		},
+		Labels: map[string]string{
+			"env":               "prod",
+			"example.com/owner": "ops",
+		},
+		Pair:    [2]int{0, 2},
+		Timeout: 5 * time.Second,
+	}
+	if actual := req; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected request=%+v but actual=%+v", expected, actual)
+	}
+
+	tests := []struct {
+		query string
+		err   error
+	}{
+		{query: "Filter.Min=300", err: nil},
+		{query: "Filter=1", err: ErrTypeMismatch},
+		{query: "Pair[2]=1", err: ErrNotFound},
+		{query: "Items[x=1", err: ErrInvalidPath},
+	}
+	for i, test := range tests {
+		values, _ := url.ParseQuery(test.query)
+		err := DecodeValues(values, &ValuesRequest{})
+		pathErr, ok := err.(*PathError)
+		if !ok {
+			t.Errorf("[i=%v] Expected path error but actual=%v for query=%q", i, err, test.query)
+			continue
+		}
+		if test.err != nil && pathErr.Err != test.err {
+			t.Errorf("[i=%v] Expected err=%v but actual=%v for query=%q", i, test.err, pathErr.Err, test.query)
+		}
+	}
+
+	// Unknown keys are ignored unless strict, leaving the object untouched.
+	for _, query := range []string{"Missing=1", "Filter.Missing=1", "Items.Missing=1&Items.Missing=2", "Refs[0].Missing=1"} {
+		values, _ := url.ParseQuery(query)
+		req := &ValuesRequest{}
+		if err := DecodeValues(values, req); err != nil || !reflect.DeepEqual(req, &ValuesRequest{}) {
+			t.Errorf("Expected request to be untouched but actual=%+v (err=%v) for query=%q", req, err, query)
+		}
+		err := DecodeValues(values, &ValuesRequest{}, WithStrictKeys())
+		if pathErr, ok := err.(*PathError); !ok || pathErr.Err != ErrNotFound {
+			t.Errorf("Expected err=%v but actual=%v for query=%q", ErrNotFound, err, query)
+		}
+	}
+
+	// Indexes from the query can't allocate arbitrarily large slices.
+	huge := url.Values{"Items[300000000].Key": {"x"}}
+	req = &ValuesRequest{}
+	if err := DecodeValues(huge, req); err == nil || err.(*PathError).Err != ErrIndexTooLarge {
+		t.Errorf("Expected err=%v but actual=%v", ErrIndexTooLarge, err)
+	}
+	if len(req.Items) != 0 {
+		t.Errorf("Expected no items to be allocated but actual len=%v", len(req.Items))
+	}
+	limited := url.Values{"Items[3].Key": {"x"}}
+	if err := DecodeValues(limited, &ValuesRequest{}, WithMaxIndex(2)); err == nil || err.(*PathError).Err != ErrIndexTooLarge {
+		t.Errorf("Expected err=%v but actual=%v", ErrIndexTooLarge, err)
+	}
+	if err := DecodeValues(limited, &ValuesRequest{Items: make([]ValuesItem, 4)}, WithMaxIndex(2)); err != nil {
+		t.Errorf("Expected existing elements to be addressable but actual err=%v", err)
+	}
+
+	if expected, actual := ErrNotSettable, DecodeValues(url.Values{}, ValuesRequest{}); actual != expected {
+		t.Errorf("Expected err=%v but actual=%v", expected, actual)
+	}
+}
+
+func TestEncodeValues(t *testing.T) {
+	active := false
+	req := &ValuesRequest{
+		Query:  "shoes",
+		Since:  time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC),
+		Tags:   []string{"a", "b"},
+		Filter: ValuesFilter{Active: &active},
+		Items: []ValuesItem{
+			{Key: "x", Count: 1},
+		},
+		Refs:    []*ValuesItem{nil, {Key: "second"}},
+		Labels:  map[string]string{"example.com/owner": "ops"},
+		Timeout: time.Minute,
+	}
+
+	values := EncodeValues(req)
+	expected := url.Values{
+		"Query":                       {"shoes"},
+		"Since":                       {"2017-06-01T00:00:00Z"},
+		"Tags":                        {"a", "b"},
+		"Filter.Active":               {"false"},
+		"Filter.Min":                  {"0"},
+		"Items[0].Key":                {"x"},
+		"Items[0].Count":              {"1"},
+		"Refs[1].Key":                 {"second"},
+		"Refs[1].Count":               {"0"},
+		`Labels["example.com/owner"]`: {"ops"},
+		"Pair[0]":                     {"0"},
+		"Pair[1]":                     {"0"},
+		"Timeout":                     {"1m0s"},
+	}
+	if actual := values; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected values=%# v but actual=%# v", expected, actual)
+	}
+
+	// Decoding the encoded values restores the original.
+	decoded := &ValuesRequest{}
+	if err := DecodeValues(values, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := req, decoded; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected round-trip=%+v but actual=%+v", expected, actual)
+	}
+
+	// Tag names are honored.
+	type Tagged struct {
+		Name  string `json:"name"`
+		Inner struct {
+			IDs []int `json:"ids"`
+		} `json:"inner"`
+	}
+	tagged := Tagged{Name: "n"}
+	tagged.Inner.IDs = []int{1, 2}
+	if expected, actual := (url.Values{"name": {"n"}, "inner.ids": {"1", "2"}}), New(WithTagName("json")).EncodeValues(tagged); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected values=%# v but actual=%# v", expected, actual)
+	}
+}
@@ -0,0 +1,116 @@
+package metaflector
+
+import (
+	"reflect"
+	"sort"
+)
+
+// node is a value reached while walking an object, along with its concrete
+// path.
+type node struct {
+	v    reflect.Value
+	path string
+}
+
+// walker navigates between nodes according to a Reflector's configuration.
+type walker struct {
+	r *Reflector
+	o *options
+}
+
+// children returns the fields, map values (sorted by key) or elements of the
+// node.
+func (w *walker) children(n node) []node {
+	var (
+		v   = indirect(n.v)
+		sep = w.o.sep()
+		out = []node{}
+	)
+
+	if lister, getter := customFields(n.v); lister != nil {
+		for _, name := range lister.MetaflectorFields() {
+			if field, ok := w.r.customField(n.v, getter, name, w.o); ok {
+				out = append(out, node{v: field, path: childPath(n.path, quoteComponent(name, sep), sep)})
+			}
+		}
+		return out
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range w.r.fields(v.Type(), w.o.tagName) {
+			out = append(out, node{v: v.Field(f.index), path: childPath(n.path, quoteComponent(f.name, sep), sep)})
+		}
+
+	case reflect.Map:
+		switch v.Type().Key().Kind() {
+		case reflect.String:
+			keys := v.MapKeys()
+			sort.Sort(byString(keys))
+			for _, k := range keys {
+				out = append(out, node{v: v.MapIndex(k), path: childPath(n.path, quoteComponent(k.String(), sep), sep)})
+			}
+		case reflect.Interface:
+			// e.g. map[interface{}]interface{} decoded from YAML.
+			for _, k := range sortedKeys(v) {
+				out = append(out, node{v: v.MapIndex(k), path: childPath(n.path, quoteComponent(keyName(k), sep), sep)})
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out = append(out, w.element(n, v, i))
+		}
+	}
+
+	return out
+}
+
+func (w *walker) child(n node, name string) (node, bool) {
+	var (
+		v   = indirect(n.v)
+		sep = w.o.sep()
+	)
+
+	if _, getter := customFields(n.v); getter != nil {
+		if value, ok := getter.MetaflectorField(name); ok {
+			return node{v: reflect.ValueOf(value), path: childPath(n.path, quoteComponent(name, sep), sep)}, true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if field := w.r.fieldByName(v, name, w.o.tagName); field.IsValid() && field.CanInterface() {
+			return node{v: field, path: childPath(n.path, quoteComponent(name, sep), sep)}, true
+		}
+
+	case reflect.Map:
+		if _, value := mapIndex(v, name); value.IsValid() {
+			return node{v: value, path: childPath(n.path, quoteComponent(name, sep), sep)}, true
+		}
+	}
+
+	return node{}, false
+}
+
+func (w *walker) element(n node, v reflect.Value, i int) node {
+	return node{v: v.Index(i), path: childPath(n.path, indexComponent(i), w.o.sep())}
+}
+
+// indirect resolves pointers and interfaces, returning the zero Value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// byString sorts string-kinded reflect.Values.
+type byString []reflect.Value
+
+func (s byString) Len() int           { return len(s) }
+func (s byString) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byString) Less(i, j int) bool { return s[i].String() < s[j].String() }
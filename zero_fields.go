@@ -0,0 +1,165 @@
+package metaflector
+
+import (
+	"reflect"
+	"sort"
+)
+
+// IsZero returns true if v is invalid or holds the zero value for its type,
+// e.g. a nil pointer, nil slice, 0 or "".  Pointers to zero values and empty
+// but non-nil slices and maps aren't zero.
+func IsZero(v reflect.Value) bool {
+	return !v.IsValid() || isZero(v)
+}
+
+// IsEmpty is like IsZero, but also counts empty slices and maps as zero.
+func IsEmpty(v reflect.Value) bool {
+	return isEmpty(v)
+}
+
+// SetFields returns the concrete paths of the terminal fields of obj which
+// hold non-zero values, e.g. "Bar.Baz.Name" or "Contents[2].Key".  See
+// ZeroFields.
+func SetFields(obj interface{}, opts ...Option) []string {
+	return defaultReflector.SetFields(obj, opts...)
+}
+
+// SetFields is the Reflector equivalent of the package-level SetFields.
+func (r *Reflector) SetFields(obj interface{}, opts ...Option) []string {
+	set, _ := r.fieldStates(obj, r.options(opts))
+	return set
+}
+
+// ZeroFields returns the concrete paths of the terminal fields of obj which
+// hold zero values, as determined by IsZero or the function given to WithZero.
+//
+// Fields are traversed as they are by TerminalFields, honoring the same
+// options, with a few differences suited to auditing: a nil pointer to a
+// struct, or an empty slice of structs, is reported as a single field rather
+// than omitted, nil slice elements are reported individually, and slices of
+// primitives and structs such as time.Time are treated as terminal fields.
+// As with TerminalFields, circular references aren't supported.
+func ZeroFields(obj interface{}, opts ...Option) []string {
+	return defaultReflector.ZeroFields(obj, opts...)
+}
+
+// ZeroFields is the Reflector equivalent of the package-level ZeroFields.
+func (r *Reflector) ZeroFields(obj interface{}, opts ...Option) []string {
+	_, zero := r.fieldStates(obj, r.options(opts))
+	return zero
+}
+
+// fieldStates partitions the terminal fields of obj into set and zero.
+func (r *Reflector) fieldStates(obj interface{}, o *options) (set []string, zero []string) {
+	a := &auditor{
+		w:    &walker{r: r, o: o},
+		zero: o.zero,
+		set:  []string{},
+		zs:   []string{},
+	}
+	if a.zero == nil {
+		a.zero = IsZero
+	}
+	a.includes, a.excludes = compilePatterns(o.patterns, o.sep())
+
+	if v := indirect(reflect.ValueOf(obj)); v.Kind() == reflect.Struct {
+		a.fields(node{v: v}, nil)
+	}
+
+	if !o.declOrder {
+		sort.Strings(a.set)
+		sort.Strings(a.zs)
+	}
+	return a.set, a.zs
+}
+
+type auditor struct {
+	w                  *walker
+	zero               func(v reflect.Value) bool
+	includes, excludes []pattern
+	set, zs            []string
+}
+
+func (a *auditor) report(n node) {
+	if a.zero(n.v) {
+		a.zs = append(a.zs, n.path)
+	} else {
+		a.set = append(a.set, n.path)
+	}
+}
+
+// fields audits the fields of struct node n.  components holds the path of n
+// without any slice indexes, for matching against patterns and depth limits.
+func (a *auditor) fields(n node, components []string) {
+	var (
+		v   = indirect(n.v)
+		o   = a.w.o
+		sep = o.sep()
+	)
+
+	for _, f := range a.w.r.fields(v.Type(), o.tagName) {
+		if o.excludesType(f.typ) {
+			continue
+		}
+
+		var (
+			child = node{
+				v:    v.Field(f.index),
+				path: childPath(n.path, quoteComponent(f.name, sep), sep),
+			}
+			childComponents = append(components[:len(components):len(components)], f.name)
+			depth           = len(childComponents)
+			terminal        = !hasFields(f.typ)
+		)
+
+		if o.maxDepth > 0 && (depth > o.maxDepth || !terminal && depth == o.maxDepth) {
+			continue
+		}
+		if !allowed(childComponents, terminal, a.includes, a.excludes) {
+			continue
+		}
+
+		if terminal {
+			a.report(child)
+			continue
+		}
+
+		a.descend(child, childComponents)
+	}
+}
+
+// descend audits the fields beneath n, which is a struct, a slice or array of
+// them, or a nil pointer to either.  Nil and empty values are reported
+// themselves, as they have no fields to speak of.
+func (a *auditor) descend(n node, components []string) {
+	switch v := indirect(n.v); v.Kind() {
+	case reflect.Struct:
+		a.fields(n, components)
+
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			a.report(n)
+		}
+		for i := 0; i < v.Len(); i++ {
+			a.descend(a.w.element(n, v, i), components)
+		}
+
+	default:
+		a.report(n)
+	}
+}
+
+// hasFields returns true if t is a struct, or a pointer to or slice or array
+// of structs, whose fields are traversed.
+func hasFields(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		case reflect.Struct:
+			return !isTerminalStruct(t)
+		default:
+			return false
+		}
+	}
+}
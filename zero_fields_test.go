@@ -0,0 +1,87 @@
+package metaflector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetAndZeroFields(t *testing.T) {
+	type (
+		Line struct {
+			SKU string
+			Qty int
+		}
+
+		Order struct {
+			ID       string
+			Placed   time.Time
+			Tags     []string
+			Notes    *string
+			Lines    []*Line
+			Shipping *Line
+			Grid     [][]Line
+			Meta     map[string]string
+		}
+	)
+
+	var (
+		empty = ""
+		order = &Order{
+			ID:    "o-1",
+			Tags:  []string{},
+			Notes: &empty,
+			Lines: []*Line{
+				{SKU: "a", Qty: 1},
+				nil,
+				{SKU: "c"},
+			},
+			Grid: [][]Line{{{Qty: 2}}, {}},
+			Meta: map[string]string{},
+		}
+	)
+
+	tests := []struct {
+		opts []Option
+		set  []string
+		zero []string
+	}{
+		{
+			set:  []string{"Grid[0][0].Qty", "Grid[1]", "ID", "Lines[0].Qty", "Lines[0].SKU", "Lines[2].SKU", "Meta", "Notes", "Tags"},
+			zero: []string{"Grid[0][0].SKU", "Lines[1]", "Lines[2].Qty", "Placed", "Shipping"},
+		},
+		{
+			opts: []Option{WithZero(IsEmpty), WithDeclarationOrder()},
+			set:  []string{"ID", "Notes", "Lines[0].SKU", "Lines[0].Qty", "Lines[2].SKU", "Grid[0][0].Qty"},
+			zero: []string{"Placed", "Tags", "Lines[1]", "Lines[2].Qty", "Shipping", "Grid[0][0].SKU", "Grid[1]", "Meta"},
+		},
+		{
+			opts: []Option{WithFilter("Lines.*"), WithZero(func(v reflect.Value) bool {
+				return v.Kind() == reflect.Int && v.Int() < 2 || IsZero(v)
+			})},
+			set:  []string{"Lines[0].SKU", "Lines[2].SKU"},
+			zero: []string{"Lines[0].Qty", "Lines[1]", "Lines[2].Qty"},
+		},
+		{
+			opts: []Option{WithMaxDepth(1), WithExcludedTypes(reflect.TypeOf(time.Time{}))},
+			set:  []string{"ID", "Meta", "Notes", "Tags"},
+			zero: []string{},
+		},
+	}
+
+	for i, test := range tests {
+		if expected, actual := test.set, SetFields(order, test.opts...); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected set fields=%# v but actual=%# v", i, expected, actual)
+		}
+		if expected, actual := test.zero, ZeroFields(order, test.opts...); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[i=%v] Expected zero fields=%# v but actual=%# v", i, expected, actual)
+		}
+	}
+
+	if expected, actual := []string{"Bar.Baz.Active", "Bar.Baz.ContentPtrs", "Bar.Baz.Contents", "Bar.Baz.Map", "Bar.Baz.Multiplier", "Bar.Baz.Name", "Bar.Baz.PtrA", "Bar.Baz.PtrB", "Bar.Baz.PtrContentPtrPtrs", "Bar.Stock", "Contents", "StructPtr"}, ZeroFields(Foo{}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected zero fields=%# v but actual=%# v", expected, actual)
+	}
+	if expected, actual := []string{}, SetFields("not a struct"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected set fields=%# v but actual=%# v", expected, actual)
+	}
+}